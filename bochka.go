@@ -2,10 +2,15 @@ package bochka
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
 )
 
 // ContainerService defines the interface that any container service must implement
@@ -16,23 +21,110 @@ type ContainerService interface {
 	GetContainer() testcontainers.Container
 }
 
+// multiContainerService is implemented by the services running several containers, so that the
+// logs of all of them are printed and written, not only of the one returned by GetContainer.
+type multiContainerService interface {
+	containerServices() []ContainerService
+}
+
+// containerServices returns the services of the containers run by the service.
+func containerServices(service ContainerService) []ContainerService {
+	if multi, ok := service.(multiContainerService); ok {
+		return multi.containerServices()
+	}
+
+	return []ContainerService{service}
+}
+
 // ContainerConfig holds common configuration for any container
 type ContainerConfig struct {
-	EnvVars      map[string]string
-	Image        string
-	Version      string
-	NetworkAlias string
-	HostPort     string
-	ExposedPorts []string
+	EnvVars         map[string]string
+	Image           string
+	Version         string
+	NetworkAlias    string
+	HostPort        string
+	ExposedPorts    []string
+	WaitingFor      wait.Strategy   // replaces the default readiness check of the service
+	AdditionalWaits []wait.Strategy // checked in addition to the readiness check
+	StartupTimeout  time.Duration   // limits the whole readiness check
+	LogConsumers    []testcontainers.LogConsumer
+}
+
+// logConsumerConfig returns the log production config for the request, or nil if there are no consumers.
+func (c ContainerConfig) logConsumerConfig() *testcontainers.LogConsumerConfig {
+	if len(c.LogConsumers) == 0 {
+		return nil
+	}
+
+	return &testcontainers.LogConsumerConfig{Consumers: c.LogConsumers}
+}
+
+// waitStrategy composes the default readiness check of the service with the configured
+// overrides. It returns nil if there is nothing to wait for.
+func (c ContainerConfig) waitStrategy(defaults ...wait.Strategy) wait.Strategy {
+	strategies := defaults
+	if c.WaitingFor != nil {
+		strategies = []wait.Strategy{c.WaitingFor}
+	}
+	strategies = append(slices.Clip(strategies), c.AdditionalWaits...)
+
+	if len(strategies) == 0 {
+		return nil
+	}
+
+	all := wait.ForAll(strategies...)
+	if c.StartupTimeout > 0 {
+		all = all.WithStartupTimeoutDefault(c.StartupTimeout).WithDeadline(c.StartupTimeout)
+	}
+
+	return all
 }
 
 // Bochka is a generic test helper for managing container lifecycles.
+// It works with any testing.TB. A nil testing.TB is accepted for use outside of tests,
+// e.g. in TestMain; in that case no cleanup is registered and Close must be called explicitly.
 type Bochka[T ContainerService] struct {
 	Context context.Context
 	options
-	t       *testing.T
-	network *testcontainers.DockerNetwork
-	service T
+	t           testing.TB
+	network     *testcontainers.DockerNetwork
+	service     T
+	ownsNetwork bool // the network was created by the constructor, not passed via WithNetwork
+	closed      bool
+}
+
+// newBochka wraps the service into a Bochka and registers the test cleanup unless it is disabled.
+func newBochka[T ContainerService](t testing.TB, ctx context.Context, opts options, network *testcontainers.DockerNetwork, ownsNetwork bool, service T) *Bochka[T] {
+	b := &Bochka[T]{
+		t:           t,
+		options:     opts,
+		Context:     ctx,
+		network:     network,
+		service:     service,
+		ownsNetwork: ownsNetwork,
+	}
+
+	if opts.environment != nil {
+		// the environment owns the container lifetime, so WithoutCleanup has no effect
+		opts.environment.addWithOptions(service, &b.options)
+		if len(opts.dependsOn) > 0 {
+			opts.environment.DependsOn(service, opts.dependsOn...)
+		}
+		return b
+	}
+
+	if !opts.noCleanup {
+		t.Cleanup(b.cleanup)
+	}
+
+	return b
+}
+
+// cleanup closes the Bochka.
+func (b *Bochka[T]) cleanup() {
+	if err := b.Close(); err != nil {
+		b.t.Errorf("failed to close %s container: %v", b.service.HostAlias(), err)
+	}
 }
 
 // NetworkName returns the name of the Docker network used by the container.
@@ -45,33 +137,82 @@ func (b *Bochka[T]) Service() T {
 	return b.service
 }
 
-// Close terminates the container
+// Close terminates the container and removes the network if it was created by the constructor.
+// If the test failed, the container logs are printed first. Networks passed via WithNetwork
+// are left untouched. Subsequent calls do nothing.
 func (b *Bochka[T]) Close() error {
-	return b.service.Close()
+	if b.closed {
+		return nil
+	}
+
+	b.closed = true
+
+	if b.t.Failed() {
+		// b.Context is usually canceled by the time the cleanup runs
+		printLogs(context.Background(), b.t, b.service)
+	}
+
+	// the logs are gone once the container is terminated
+	artifactErr := b.writeLogArtifact(context.Background(), b.t, b.service)
+
+	if err := b.service.Close(); err != nil {
+		// the network cannot be removed while the container is still attached
+		return errors.Join(artifactErr, err)
+	}
+
+	if !b.ownsNetwork {
+		return artifactErr
+	}
+
+	if err := b.network.Remove(context.Background()); err != nil {
+		return errors.Join(artifactErr, fmt.Errorf("failed to remove network %s: %w", b.network.Name, err))
+	}
+
+	return artifactErr
 }
 
 func (b *Bochka[T]) Start() error {
 	return b.Service().Start(b.Context)
 }
 
+// PrintLogs prints the container logs to the test output.
 func (b *Bochka[T]) PrintLogs() {
-	logReader, err := b.Service().GetContainer().Logs(b.Context)
+	printLogs(b.Context, b.t, b.service)
+}
+
+// printLogs prints the logs of the service containers to the test output.
+func printLogs(ctx context.Context, t testing.TB, service ContainerService) {
+	for _, node := range containerServices(service) {
+		logs, err := readLogs(ctx, node.GetContainer())
+		if err != nil {
+			t.Errorf("failed to get %s container logs: %v", node.HostAlias(), err)
+			continue
+		}
+
+		if logs == nil {
+			continue
+		}
+
+		t.Logf("%s container logs:\n%s", node.HostAlias(), logs)
+	}
+}
+
+// readLogs returns the full log of the container, or nil if the container was not created.
+func readLogs(ctx context.Context, container testcontainers.Container) (logs []byte, err error) {
+	if container == nil {
+		return nil, nil
+	}
+
+	logReader, err := container.Logs(ctx)
 	if err != nil {
-		b.t.Errorf("failed to get %s container logs: %v", b.service.HostAlias(), err)
-		return
+		return nil, err
 	}
 
 	defer func() {
-		if err := logReader.Close(); err != nil {
-			b.t.Errorf("failed to close log reader: %v", err)
+		if closeErr := logReader.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close log reader: %w", closeErr)
 		}
 	}()
 
-	logs, err := io.ReadAll(logReader)
-	if err != nil {
-		b.t.Errorf("failed to get %s container logs: %v", b.service.HostAlias(), err)
-		return
-	}
-
-	b.t.Logf("%s container logs:\n%s", b.service.HostAlias(), logs)
+	return io.ReadAll(logReader)
 }
@@ -0,0 +1,302 @@
+package bochka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// Environment is a group of container services sharing one Docker network.
+// Independent services are started concurrently, dependents are started after their
+// prerequisites are ready. The services are closed in reverse order.
+type Environment struct {
+	Context context.Context
+	options
+	t           testing.TB
+	network     *testcontainers.DockerNetwork
+	ownsNetwork bool
+
+	mu        sync.Mutex
+	services  []ContainerService
+	dependsOn map[ContainerService][]ContainerService
+	artifacts map[ContainerService]*options // options of the services passed WithLogArtifacts
+	closed    bool
+}
+
+// NewEnvironment creates a new environment with a shared Docker network. Services join it via
+// WithEnvironment. Unless WithoutCleanup is passed, the environment is closed on test cleanup.
+func NewEnvironment(t testing.TB, ctx context.Context, settings ...option) *Environment {
+	t = reporter(t)
+
+	var opts options
+	opts.applyOptions(settings)
+
+	network := opts.network
+	ownsNetwork := network == nil
+	if ownsNetwork {
+		var err error
+		network, err = NewNetwork(ctx)
+		if err != nil {
+			t.Fatalf("failed to create network: %v", err)
+		}
+	}
+
+	env := &Environment{
+		Context:     ctx,
+		options:     opts,
+		t:           t,
+		network:     network,
+		ownsNetwork: ownsNetwork,
+		dependsOn:   make(map[ContainerService][]ContainerService),
+	}
+
+	if !opts.noCleanup {
+		t.Cleanup(env.cleanup)
+	}
+
+	return env
+}
+
+// Network returns the Docker network shared by the services.
+func (e *Environment) Network() *testcontainers.DockerNetwork {
+	return e.network
+}
+
+// NetworkName returns the name of the Docker network shared by the services.
+func (e *Environment) NetworkName() string {
+	return e.network.Name
+}
+
+// Add adds services to the environment. Services created with WithEnvironment are added
+// automatically; custom services must be attached to Network() by the caller. Services the
+// environment already holds are skipped.
+func (e *Environment) Add(services ...ContainerService) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, service := range services {
+		if !slices.Contains(e.services, service) {
+			e.services = append(e.services, service)
+		}
+	}
+}
+
+// addWithOptions adds the service created with WithEnvironment, keeping its options, so that its
+// own WithLogArtifacts takes precedence over the one of the environment.
+func (e *Environment) addWithOptions(service ContainerService, opts *options) {
+	e.Add(service)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !opts.logArtifacts {
+		return
+	}
+
+	if e.artifacts == nil {
+		e.artifacts = make(map[ContainerService]*options)
+	}
+	e.artifacts[service] = opts
+}
+
+// Services returns the services of the environment in the order they were added.
+func (e *Environment) Services() []ContainerService {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return slices.Clone(e.services)
+}
+
+// DependsOn declares that the service must be started only after the prerequisites are ready,
+// i.e. after their wait strategies have passed. All of them must be added to the environment.
+func (e *Environment) DependsOn(service ContainerService, prerequisites ...ContainerService) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.dependsOn[service] = append(e.dependsOn[service], prerequisites...)
+}
+
+// Start starts the services and waits for them to become ready. Services without pending
+// prerequisites are started concurrently. All startup errors are returned joined.
+func (e *Environment) Start() error {
+	e.mu.Lock()
+	services := slices.Clone(e.services)
+	dependsOn := maps.Clone(e.dependsOn)
+	e.mu.Unlock()
+
+	if err := validateDependencies(services, dependsOn); err != nil {
+		return err
+	}
+
+	ready := make(map[ContainerService]chan struct{}, len(services))
+	for _, service := range services {
+		ready[service] = make(chan struct{})
+	}
+
+	errs := make([]error, len(services))
+	failed := make(map[ContainerService]bool, len(services))
+	var failedMu sync.Mutex
+
+	var wg sync.WaitGroup
+	for i, service := range services {
+		wg.Go(func() {
+			defer close(ready[service])
+
+			for _, prerequisite := range dependsOn[service] {
+				<-ready[prerequisite]
+
+				failedMu.Lock()
+				prerequisiteFailed := failed[prerequisite]
+				failedMu.Unlock()
+
+				if prerequisiteFailed {
+					errs[i] = fmt.Errorf("%s was not started: prerequisite %s failed", service.HostAlias(), prerequisite.HostAlias())
+					break
+				}
+			}
+
+			if errs[i] == nil {
+				if err := service.Start(e.Context); err != nil {
+					errs[i] = fmt.Errorf("failed to start %s: %w", service.HostAlias(), err)
+				}
+			}
+
+			if errs[i] != nil {
+				failedMu.Lock()
+				failed[service] = true
+				failedMu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// validateDependencies checks that all prerequisites belong to the environment and that
+// the dependency graph has no cycles.
+func validateDependencies(services []ContainerService, dependsOn map[ContainerService][]ContainerService) error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	state := make(map[ContainerService]int, len(services))
+	for service, prerequisites := range dependsOn {
+		if !slices.Contains(services, service) {
+			return fmt.Errorf("%s has dependencies but is not added to the environment", service.HostAlias())
+		}
+
+		for _, prerequisite := range prerequisites {
+			if !slices.Contains(services, prerequisite) {
+				return fmt.Errorf("%s depends on %s which is not added to the environment", service.HostAlias(), prerequisite.HostAlias())
+			}
+		}
+	}
+
+	var path []ContainerService
+	var visit func(service ContainerService) error
+	visit = func(service ContainerService) error {
+		switch state[service] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := path[slices.Index(path, service):]
+			aliases := make([]string, 0, len(cycle)+1)
+			for _, node := range cycle {
+				aliases = append(aliases, node.HostAlias())
+			}
+			aliases = append(aliases, service.HostAlias())
+			return fmt.Errorf("dependency cycle: %s", strings.Join(aliases, " -> "))
+		}
+
+		state[service] = visiting
+		path = append(path, service)
+		for _, prerequisite := range dependsOn[service] {
+			if err := visit(prerequisite); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[service] = visited
+
+		return nil
+	}
+
+	for _, service := range services {
+		if err := visit(service); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close terminates the services in reverse order and removes the network if it was created
+// by the environment. If the test failed, the logs of the services are printed first.
+// Subsequent calls do nothing.
+func (e *Environment) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+
+	if e.t.Failed() {
+		// e.Context is usually canceled by the time the cleanup runs
+		for _, service := range e.services {
+			printLogs(context.Background(), e.t, service)
+		}
+	}
+
+	var errs []error
+	closeFailed := false
+	for _, service := range slices.Backward(e.services) {
+		// the logs are gone once the container is terminated
+		artifacts := &e.options
+		if opts, ok := e.artifacts[service]; ok {
+			artifacts = opts
+		}
+
+		if err := artifacts.writeLogArtifact(context.Background(), e.t, service); err != nil {
+			errs = append(errs, err)
+		}
+
+		if err := service.Close(); err != nil {
+			closeFailed = true
+			errs = append(errs, fmt.Errorf("failed to close %s: %w", service.HostAlias(), err))
+		}
+	}
+
+	// the network cannot be removed while containers are still attached
+	if e.ownsNetwork && !closeFailed {
+		if err := e.network.Remove(context.Background()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove network %s: %w", e.network.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// PrintLogs prints the logs of all services to the test output.
+func (e *Environment) PrintLogs() {
+	for _, service := range e.Services() {
+		printLogs(e.Context, e.t, service)
+	}
+}
+
+// cleanup closes the environment.
+func (e *Environment) cleanup() {
+	if err := e.Close(); err != nil {
+		e.t.Errorf("failed to close environment: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package bochka
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// stubService is a ContainerService without a container that counts its starts.
+type stubService struct {
+	alias  string
+	starts atomic.Int32
+}
+
+func (s *stubService) Start(context.Context) error {
+	s.starts.Add(1)
+	return nil
+}
+
+func (*stubService) Close() error {
+	return nil
+}
+
+func (s *stubService) HostAlias() string {
+	return s.alias
+}
+
+func (*stubService) GetContainer() testcontainers.Container {
+	return nil
+}
+
+func Test_EnvironmentAddDuplicates(t *testing.T) {
+	env := &Environment{
+		Context:   t.Context(),
+		t:         t,
+		dependsOn: make(map[ContainerService][]ContainerService),
+	}
+
+	first, second := &stubService{alias: "first"}, &stubService{alias: "second"}
+	env.Add(first)
+	env.Add(first, second, second)
+
+	if got := len(env.Services()); got != 2 {
+		t.Fatalf("expected 2 services, got %d", got)
+	}
+
+	if err := env.Start(); err != nil {
+		t.Fatalf("failed to start environment: %v", err)
+	}
+
+	for _, service := range []*stubService{first, second} {
+		if got := service.starts.Load(); got != 1 {
+			t.Errorf("expected %s to start once, got %d", service.alias, got)
+		}
+	}
+}
+
+func Test_EnvironmentServiceLogArtifacts(t *testing.T) {
+	env := &Environment{Context: t.Context(), t: t}
+
+	plain, withArtifacts := &stubService{alias: "plain"}, &stubService{alias: "artifacts"}
+
+	var plainOpts, artifactOpts options
+	plainOpts.applyOptions(nil)
+	artifactOpts.applyOptions([]option{WithLogArtifacts(t.TempDir(), true)})
+
+	env.addWithOptions(plain, &plainOpts)
+	env.addWithOptions(withArtifacts, &artifactOpts)
+
+	if _, ok := env.artifacts[plain]; ok {
+		t.Error("expected the environment options to apply to the service without WithLogArtifacts")
+	}
+	if env.artifacts[withArtifacts] != &artifactOpts {
+		t.Error("expected the options of the service with WithLogArtifacts to be kept")
+	}
+}
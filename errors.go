@@ -0,0 +1,204 @@
+package bochka
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	faststrconv "github.com/kaatinga/strconv"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// StartPhase is the phase of the container startup.
+type StartPhase uint8
+
+const (
+	PhasePull    StartPhase = iota + 1 // pulling the image
+	PhaseCreate                        // creating the container
+	PhaseStart                         // starting the container
+	PhaseWait                          // waiting for the readiness check
+	PhaseInspect                       // getting the host and mapped ports
+	PhaseSetup                         // preparing the started service, e.g. applying migrations
+)
+
+// String returns the name of the phase.
+func (p StartPhase) String() string {
+	switch p {
+	case PhasePull:
+		return "pull"
+	case PhaseCreate:
+		return "create"
+	case PhaseStart:
+		return "start"
+	case PhaseWait:
+		return "wait"
+	case PhaseInspect:
+		return "inspect"
+	case PhaseSetup:
+		return "setup"
+	default:
+		return "unknown"
+	}
+}
+
+// startErrorLogLines is the number of container log lines attached to a wait phase error.
+const startErrorLogLines = 50
+
+// StartError is returned by the Start methods of the services when the container fails to start.
+// Use errors.As to inspect it.
+type StartError struct {
+	Phase StartPhase
+	Alias string // network alias of the service
+	Image string // image reference, e.g. "postgres:17.5"
+	Logs  string // tail of the container logs, set if the wait phase failed
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *StartError) Error() string {
+	msg := fmt.Sprintf("%s phase failed for %s (%s): %v", e.Phase, e.Alias, e.Image, e.Err)
+	if e.Logs != "" {
+		msg += "\ncontainer logs:\n" + e.Logs
+	}
+
+	return msg
+}
+
+// Unwrap returns the cause.
+func (e *StartError) Unwrap() error {
+	return e.Err
+}
+
+// startContainer creates and starts the container, classifying failures by the phase.
+// The returned container may be non-nil even on error, so it can be terminated.
+func startContainer(ctx context.Context, req testcontainers.ContainerRequest, alias string) (testcontainers.Container, error) {
+	watcher := &pullWatcher{}
+	req.ImageSubstitutors = append(slices.Clone(req.ImageSubstitutors), watcher)
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{ContainerRequest: req})
+	if err != nil {
+		phase := PhaseCreate
+		if isPullError(err, watcher.reached) {
+			phase = PhasePull
+		}
+
+		return container, &StartError{Phase: phase, Alias: alias, Image: req.Image, Err: err}
+	}
+
+	if err = container.Start(ctx); err != nil {
+		startErr := &StartError{Phase: PhaseStart, Alias: alias, Image: req.Image, Err: err}
+		if strings.Contains(err.Error(), "wait until ready:") {
+			startErr.Phase = PhaseWait
+			startErr.Logs = logTail(container, startErrorLogLines)
+		}
+
+		return container, startErr
+	}
+
+	return container, nil
+}
+
+// pullWatcher is an image substitutor that keeps the image as is and records that
+// testcontainers has got to the image inspection and pull.
+type pullWatcher struct {
+	reached bool
+}
+
+// Description implements testcontainers.ImageSubstitutor.
+func (*pullWatcher) Description() string {
+	return "bochka pull watcher"
+}
+
+// Substitute implements testcontainers.ImageSubstitutor.
+func (w *pullWatcher) Substitute(image string) (string, error) {
+	w.reached = true
+
+	return image, nil
+}
+
+// pullStepPrefixes are the prefixes testcontainers adds to the errors of the steps that follow
+// the image substitution.
+var pullStepPrefixes = []string{
+	"failed to substitute image",
+	"invalid platform",
+	"expose host ports:",
+	"container create:",
+	"network connect:",
+	"created hook:",
+}
+
+// isPullError reports whether the testcontainers.GenericContainer error comes from the image
+// inspection or pull. It relies on the error format of testcontainers-go v0.42.0: GenericContainer
+// prefixes the error with "create container: ", the image inspection and pull return the Docker
+// daemon or context error unwrapped, and the later steps add their own prefixes. The errors raised
+// before the watcher is reached, e.g. request validation and default network setup, are not pull errors.
+func isPullError(err error, watcherReached bool) bool {
+	if !watcherReached {
+		return false
+	}
+
+	msg := strings.TrimPrefix(err.Error(), "create container: ")
+	for _, prefix := range pullStepPrefixes {
+		if strings.HasPrefix(msg, prefix) {
+			return false
+		}
+	}
+
+	return strings.HasPrefix(msg, "Error response from daemon:") ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// containerHost returns the host where the container ports are exposed.
+func containerHost(ctx context.Context, container testcontainers.Container, alias, image string) (string, error) {
+	host, err := container.Host(ctx)
+	if err != nil {
+		return "", &StartError{Phase: PhaseInspect, Alias: alias, Image: image, Err: err}
+	}
+
+	return host, nil
+}
+
+// mappedPort returns the host port mapped to the container port.
+func mappedPort(ctx context.Context, container testcontainers.Container, alias, image, port string) (uint16, error) {
+	mapped, err := container.MappedPort(ctx, port)
+	if err != nil {
+		return 0, &StartError{Phase: PhaseInspect, Alias: alias, Image: image, Err: err}
+	}
+
+	hostPort, err := faststrconv.GetUint16(mapped.Port())
+	if err != nil {
+		return 0, &StartError{Phase: PhaseInspect, Alias: alias, Image: image, Err: err}
+	}
+
+	return hostPort, nil
+}
+
+// logTail returns the last lines of the container log, or an empty string if the log is unavailable.
+func logTail(container testcontainers.Container, lines int) string {
+	// the startup context has usually expired when the readiness check fails
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	logs, err := readLogs(ctx, container)
+	if err != nil || len(logs) == 0 {
+		return ""
+	}
+
+	logs = bytes.TrimRight(logs, "\n")
+	for i := len(logs) - 1; i >= 0; i-- {
+		if logs[i] != '\n' {
+			continue
+		}
+
+		lines--
+		if lines == 0 {
+			return string(logs[i+1:])
+		}
+	}
+
+	return string(logs)
+}
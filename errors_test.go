@@ -0,0 +1,37 @@
+package bochka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func Test_isPullError(t *testing.T) {
+	// formatted as by the Docker client
+	daemonErr := fmt.Errorf("%s %w", "Error response from daemon:", errors.New("manifest for redis:no-such-tag not found: manifest unknown"))
+
+	tests := []struct {
+		name    string
+		err     error
+		reached bool
+		want    bool
+	}{
+		{"pull", fmt.Errorf("create container: %w", daemonErr), true, true},
+		{"pull timeout", fmt.Errorf("create container: %w", context.DeadlineExceeded), true, true},
+		{"validation", fmt.Errorf("create container: %w", errors.New("you must specify either a build context or an image")), false, false},
+		{"default network", fmt.Errorf("create container: ensure default network: %w", daemonErr), false, false},
+		{"host ports", fmt.Errorf("create container: expose host ports: %w", daemonErr), true, false},
+		{"create", fmt.Errorf("create container: container create: %w", daemonErr), true, false},
+		{"created hook", fmt.Errorf("create container: created hook: %w", context.DeadlineExceeded), true, false},
+		{"pre-create hook", fmt.Errorf("create container: %w", errors.New("invalid port specification: \"abc\"")), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPullError(tt.err, tt.reached); got != tt.want {
+				t.Errorf("isPullError(%q, %t) = %t, want %t", tt.err, tt.reached, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package bochka
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	faststrconv "github.com/kaatinga/strconv"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/network"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// GenericSpec describes an arbitrary container started by NewGeneric.
+type GenericSpec struct {
+	Image     string            // image name without the tag, e.g. "docker.io/library/nginx"
+	Version   string            // image tag, e.g. "1.27-alpine"
+	HostAlias string            // network alias of the container
+	Ports     map[string]string // container ports by name, e.g. {"http": "80/tcp"}
+	Cmd       []string          // command to run, the image default is used if empty
+	Env       map[string]string // environment variables, WithEnvVars overrides them
+	// WaitingFor is the readiness check. If it is nil, bochka waits until all ports are listening.
+	WaitingFor wait.Strategy
+}
+
+// GenericService implements ContainerService for an arbitrary image described by GenericSpec.
+type GenericService struct {
+	Container testcontainers.Container
+	network   *testcontainers.DockerNetwork
+	config    ContainerConfig
+	spec      GenericSpec
+	ports     map[string]network.Port // parsed container ports by name
+	host      string
+	mapped    map[string]uint16 // mapped host ports by name
+}
+
+// Start starts the container and sets up connection details. Returns error on failure.
+func (g *GenericService) Start(ctx context.Context) error {
+	envVars := make(map[string]string, len(g.spec.Env)+len(g.config.EnvVars))
+	maps.Copy(envVars, g.spec.Env)
+	maps.Copy(envVars, g.config.EnvVars)
+
+	exposedPorts := make([]string, 0, len(g.ports))
+	portBindings := make(network.PortMap, len(g.ports))
+	for _, port := range g.ports {
+		exposedPorts = append(exposedPorts, port.String())
+		portBindings[port] = []network.PortBinding{{HostIP: AnyIP}}
+	}
+
+	// a host port can only be chosen unambiguously for a single port
+	if len(g.ports) == 1 {
+		for port := range portBindings {
+			portBindings[port] = []network.PortBinding{{HostIP: AnyIP, HostPort: g.config.HostPort}}
+		}
+	}
+
+	var defaultWaits []wait.Strategy
+	if g.spec.WaitingFor != nil {
+		defaultWaits = append(defaultWaits, g.spec.WaitingFor)
+	} else {
+		for _, port := range exposedPorts {
+			defaultWaits = append(defaultWaits, wait.ForListeningPort(port))
+		}
+	}
+
+	containerReq := testcontainers.ContainerRequest{
+		Image:        g.config.Image + ":" + g.config.Version,
+		Cmd:          g.spec.Cmd,
+		ExposedPorts: exposedPorts,
+		Env:          envVars,
+		WaitingFor:   g.config.waitStrategy(defaultWaits...),
+		Networks:     []string{g.network.Name},
+		NetworkAliases: map[string][]string{
+			g.network.Name: {g.spec.HostAlias},
+		},
+		HostConfigModifier: func(hostConfig *container.HostConfig) {
+			hostConfig.PortBindings = portBindings
+			hostConfig.AutoRemove = true
+		},
+		LogConsumerCfg: g.config.logConsumerConfig(),
+	}
+
+	var err error
+	g.Container, err = startContainer(ctx, containerReq, g.spec.HostAlias)
+	if err != nil {
+		return err
+	}
+
+	g.host, err = containerHost(ctx, g.Container, g.spec.HostAlias, containerReq.Image)
+	if err != nil {
+		return err
+	}
+
+	g.mapped = make(map[string]uint16, len(g.ports))
+	for name, port := range g.ports {
+		g.mapped[name], err = mappedPort(ctx, g.Container, g.spec.HostAlias, containerReq.Image, port.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close terminates the container.
+func (g *GenericService) Close() error {
+	if g.Container == nil {
+		return nil
+	}
+
+	return g.Container.Terminate(context.Background())
+}
+
+// NetworkName returns the name of the Docker network used by the container.
+func (g *GenericService) NetworkName() string {
+	return g.network.Name
+}
+
+// Host returns the host address of the container.
+func (g *GenericService) Host() string {
+	return g.host
+}
+
+// Port returns the mapped host port for the named container port, or 0 if there is no such port.
+func (g *GenericService) Port(name string) uint16 {
+	return g.mapped[name]
+}
+
+// Addr returns host:port for the named container port.
+func (g *GenericService) Addr(name string) string {
+	return g.Host() + ":" + faststrconv.Uint162String(g.Port(name))
+}
+
+// InternalAddr returns alias:port of the named container port for connections from other
+// containers on the same network.
+func (g *GenericService) InternalAddr(name string) string {
+	return g.spec.HostAlias + ":" + faststrconv.Uint162String(g.ports[name].Num())
+}
+
+// HostAlias returns the network alias for the container.
+func (g *GenericService) HostAlias() string {
+	return g.spec.HostAlias
+}
+
+// GetContainer returns the underlying container service.
+func (g *GenericService) GetContainer() testcontainers.Container {
+	return g.Container
+}
+
+// NewGeneric creates a test helper for an arbitrary image described by the spec.
+// WithCustomImage and WithEnvVars override the spec; WithPort sets the host port only if the spec
+// declares exactly one port, otherwise the host ports are chosen by Docker.
+func NewGeneric(t testing.TB, ctx context.Context, spec GenericSpec, settings ...option) *Bochka[*GenericService] {
+	t = reporter(t)
+
+	opts := options{
+		image:   spec.Image,
+		version: spec.Version,
+	}
+
+	opts.applyOptions(settings)
+
+	if opts.image == "" || opts.version == "" {
+		t.Fatalf("generic container requires an image and a version")
+	}
+
+	if spec.HostAlias == "" {
+		t.Fatalf("generic container %s requires a host alias", opts.image)
+	}
+
+	ports := make(map[string]network.Port, len(spec.Ports))
+	for name, rawPort := range spec.Ports {
+		port, err := network.ParsePort(rawPort)
+		if err != nil {
+			t.Fatalf("failed to parse %s port %q: %v", spec.HostAlias, name, err)
+		}
+		ports[name] = port
+	}
+
+	net := opts.network
+	ownsNetwork := net == nil
+	if ownsNetwork {
+		var err error
+		net, err = NewNetwork(ctx)
+		if err != nil {
+			t.Fatalf("failed to create network: %v", err)
+		}
+	}
+
+	service := &GenericService{
+		network: net,
+		spec:    spec,
+		ports:   ports,
+		config:  opts.containerConfig(t, spec.HostAlias),
+	}
+
+	return newBochka(t, ctx, opts, net, ownsNetwork, service)
+}
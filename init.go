@@ -14,5 +14,6 @@ func init() {
 	natsExposedPort, _ = network.ParsePort(natsPort + "/tcp")
 	postgresExposedPort, _ = network.ParsePort(postgresPort + "/tcp")
 	redisExposedPort, _ = network.ParsePort(redisPort + "/tcp")
+	pgbouncerExposedPort, _ = network.ParsePort(pgbouncerPort + "/tcp")
 	AnyIP, _ = netip.ParseAddr("0.0.0.0")
 }
@@ -0,0 +1,145 @@
+package bochka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// LogFilter decides whether a container log line is forwarded to the test log.
+// The stream is either testcontainers.StdoutLog or testcontainers.StderrLog.
+type LogFilter func(stream, line string) bool
+
+// LogMatching forwards the lines matching the regular expression.
+func LogMatching(re *regexp.Regexp) LogFilter {
+	return func(_, line string) bool {
+		return re.MatchString(line)
+	}
+}
+
+// LogLevels forwards the lines that contain any of the levels as a separate word, case-insensitively,
+// e.g. LogLevels("ERROR", "WARN", "FATAL").
+func LogLevels(levels ...string) LogFilter {
+	quoted := make([]string, 0, len(levels))
+	for _, level := range levels {
+		quoted = append(quoted, regexp.QuoteMeta(level))
+	}
+
+	return LogMatching(regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`))
+}
+
+// LogStderr forwards only the lines written to stderr.
+func LogStderr() LogFilter {
+	return func(stream, _ string) bool {
+		return stream == testcontainers.StderrLog
+	}
+}
+
+// testLogConsumer forwards container log lines to the test log prefixed with the host alias.
+type testLogConsumer struct {
+	t       testing.TB
+	alias   string
+	filters []LogFilter
+
+	mu      sync.Mutex
+	stopped bool
+}
+
+// newTestLogConsumer creates a consumer that stops forwarding when the test finishes,
+// as logging after that point panics.
+func newTestLogConsumer(t testing.TB, alias string, filters []LogFilter) *testLogConsumer {
+	consumer := &testLogConsumer{
+		t:       t,
+		alias:   alias,
+		filters: filters,
+	}
+
+	t.Cleanup(func() {
+		consumer.mu.Lock()
+		defer consumer.mu.Unlock()
+
+		consumer.stopped = true
+	})
+
+	return consumer
+}
+
+// Accept implements testcontainers.LogConsumer.
+func (c *testLogConsumer) Accept(log testcontainers.Log) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return
+	}
+
+	for line := range strings.Lines(string(log.Content)) {
+		line = strings.TrimRight(line, "\r\n")
+		if c.accepts(log.LogType, line) {
+			c.t.Logf("[%s] %s", c.alias, line)
+		}
+	}
+}
+
+func (c *testLogConsumer) accepts(stream, line string) bool {
+	for _, filter := range c.filters {
+		if !filter(stream, line) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Environment variables enabling log artifacts without code changes, e.g. in CI.
+const (
+	LogDirEnv           = "BOCHKA_LOG_DIR"             // directory for the container log files
+	LogOnFailureOnlyEnv = "BOCHKA_LOG_ON_FAILURE_ONLY" // write the files only for failed tests
+)
+
+// writeLogArtifact writes the full log of every service container to <logDir>/<TestName>/<alias>.log
+// if log artifacts are enabled.
+func (o *options) writeLogArtifact(ctx context.Context, t testing.TB, service ContainerService) error {
+	if o.logDir == "" || o.logOnFailureOnly && !t.Failed() {
+		return nil
+	}
+
+	var errs []error
+	for _, node := range containerServices(service) {
+		errs = append(errs, o.writeContainerLog(ctx, t, node))
+	}
+
+	return errors.Join(errs...)
+}
+
+// writeContainerLog writes the full container log of the service to <logDir>/<TestName>/<alias>.log.
+func (o *options) writeContainerLog(ctx context.Context, t testing.TB, service ContainerService) error {
+	logs, err := readLogs(ctx, service.GetContainer())
+	if err != nil {
+		return fmt.Errorf("failed to get %s container logs: %w", service.HostAlias(), err)
+	}
+
+	if logs == nil {
+		return nil
+	}
+
+	dir := filepath.Join(o.logDir, filepath.FromSlash(t.Name()))
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	path := filepath.Join(dir, service.HostAlias()+".log")
+	if err := os.WriteFile(path, logs, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s container logs: %w", service.HostAlias(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package bochka
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// recordingTB records the lines logged by a consumer and the registered cleanups.
+type recordingTB struct {
+	testing.TB
+	lines    []string
+	cleanups []func()
+}
+
+func (*recordingTB) Helper() {}
+
+func (r *recordingTB) Logf(format string, args ...any) {
+	r.lines = append(r.lines, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func stdout(content string) testcontainers.Log {
+	return testcontainers.Log{LogType: testcontainers.StdoutLog, Content: []byte(content)}
+}
+
+func stderr(content string) testcontainers.Log {
+	return testcontainers.Log{LogType: testcontainers.StderrLog, Content: []byte(content)}
+}
+
+func Test_testLogConsumer(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []LogFilter
+		logs    []testcontainers.Log
+		want    []string
+	}{
+		{
+			name: "no filters",
+			logs: []testcontainers.Log{stdout("Server is ready\r\n"), stderr("first\nsecond\n")},
+			want: []string{"[nats] Server is ready", "[nats] first", "[nats] second"},
+		},
+		{
+			name:    "levels",
+			filters: []LogFilter{LogLevels("ERR", "WRN")},
+			logs: []testcontainers.Log{
+				stdout("[INF] Listening for client connections\n"),
+				stdout("[ERR] Client connection closed\n"),
+				stdout("[wrn] Slow consumer detected\n"),
+				stdout("[INF] ERRORS are not a level\n"),
+			},
+			want: []string{"[nats] [ERR] Client connection closed", "[nats] [wrn] Slow consumer detected"},
+		},
+		{
+			name:    "matching",
+			filters: []LogFilter{LogMatching(regexp.MustCompile(`Server is ready|JetStream`))},
+			logs:    []testcontainers.Log{stdout("Starting nats-server\nStarting JetStream\nServer is ready\n")},
+			want:    []string{"[nats] Starting JetStream", "[nats] Server is ready"},
+		},
+		{
+			name:    "stderr",
+			filters: []LogFilter{LogStderr()},
+			logs:    []testcontainers.Log{stdout("to stdout\n"), stderr("to stderr\n")},
+			want:    []string{"[nats] to stderr"},
+		},
+		{
+			name:    "all filters apply",
+			filters: []LogFilter{LogStderr(), LogLevels("ERR")},
+			logs:    []testcontainers.Log{stdout("[ERR] to stdout\n"), stderr("[INF] to stderr\n"), stderr("[ERR] to stderr\n")},
+			want:    []string{"[nats] [ERR] to stderr"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &recordingTB{}
+			consumer := newTestLogConsumer(tb, "nats", tt.filters)
+			for _, log := range tt.logs {
+				consumer.Accept(log)
+			}
+
+			if !slices.Equal(tb.lines, tt.want) {
+				t.Errorf("logged lines:\ngot  %q\nwant %q", tb.lines, tt.want)
+			}
+		})
+	}
+}
+
+func Test_testLogConsumerStopsOnCleanup(t *testing.T) {
+	tb := &recordingTB{}
+	consumer := newTestLogConsumer(tb, "nats", nil)
+	consumer.Accept(stdout("before\n"))
+
+	for _, cleanup := range tb.cleanups {
+		cleanup()
+	}
+	consumer.Accept(stdout("after\n"))
+
+	if want := []string{"[nats] before"}; !slices.Equal(tb.lines, want) {
+		t.Errorf("logged lines:\ngot  %q\nwant %q", tb.lines, want)
+	}
+}
+
+func Test_containerServices(t *testing.T) {
+	aliases := func(service ContainerService) []string {
+		var aliases []string
+		for _, node := range containerServices(service) {
+			aliases = append(aliases, node.HostAlias())
+		}
+
+		return aliases
+	}
+
+	single := &PostgresService{alias: "postgres"}
+	if got, want := aliases(single), []string{"postgres"}; !slices.Equal(got, want) {
+		t.Errorf("single service: got %q, want %q", got, want)
+	}
+
+	set := &PostgresReplicaSet{
+		primary:  single,
+		replicas: []*PostgresService{{alias: "postgres-replica-1"}, {alias: "postgres-replica-2"}},
+	}
+	if got, want := aliases(set), []string{"postgres", "postgres-replica-1", "postgres-replica-2"}; !slices.Equal(got, want) {
+		t.Errorf("replica set: got %q, want %q", got, want)
+	}
+
+	cluster := &RedisClusterService{nodes: []*RedisService{{alias: "redis-node-1"}, {alias: "redis-node-2"}, {alias: "redis-node-3"}}}
+	if got, want := aliases(cluster), []string{"redis-node-1", "redis-node-2", "redis-node-3"}; !slices.Equal(got, want) {
+		t.Errorf("cluster: got %q, want %q", got, want)
+	}
+}
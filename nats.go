@@ -42,8 +42,8 @@ func (n *NatsService) Start(ctx context.Context) error {
 		Cmd:          []string{"nats-server", "-js"},
 		ExposedPorts: []string{natsExposedPort.String()},
 		Env:          envVars,
-		WaitingFor: wait.ForAll(
-			wait.ForLog("Server is ready").WithStartupTimeout(30*time.Second),
+		WaitingFor: n.config.waitStrategy(
+			wait.ForLog("Server is ready"),
 			wait.ForListeningPort(natsExposedPort.String()),
 		),
 		Networks: []string{n.network.Name},
@@ -61,30 +61,21 @@ func (n *NatsService) Start(ctx context.Context) error {
 			}
 			hostConfig.AutoRemove = false // to see logs
 		},
+		LogConsumerCfg: n.config.logConsumerConfig(),
 	}
 
 	var err error
-	n.Container, err = testcontainers.GenericContainer(
-		ctx,
-		testcontainers.GenericContainerRequest{
-			ContainerRequest: containerReq,
-			Started:          true,
-		})
+	n.Container, err = startContainer(ctx, containerReq, natsHostAlias)
 	if err != nil {
 		return err
 	}
 
-	n.host, err = n.Container.Host(ctx)
+	n.host, err = containerHost(ctx, n.Container, natsHostAlias, containerReq.Image)
 	if err != nil {
 		return err
 	}
 
-	mappedPort, err := n.Container.MappedPort(ctx, natsPort)
-	if err != nil {
-		return err
-	}
-
-	n.port, err = faststrconv.GetUint16(mappedPort.Port())
+	n.port, err = mappedPort(ctx, n.Container, natsHostAlias, containerReq.Image, natsPort)
 	if err != nil {
 		return err
 	}
@@ -94,6 +85,10 @@ func (n *NatsService) Start(ctx context.Context) error {
 
 // Close terminates the NATS container.
 func (n *NatsService) Close() error {
+	if n.Container == nil {
+		return nil
+	}
+
 	return n.Container.Terminate(context.Background())
 }
 
@@ -122,19 +117,38 @@ func (n *NatsService) GetContainer() testcontainers.Container {
 	return n.Container
 }
 
+// URL returns the client connection URL for the NATS container.
+func (n *NatsService) URL() string {
+	return "nats://" + n.Host() + ":" + faststrconv.Uint162String(n.Port())
+}
+
+// InternalAddr returns alias:port for connections from other containers on the same network.
+func (n *NatsService) InternalAddr() string {
+	return natsHostAlias + ":" + natsPort
+}
+
+// InternalURL returns the client connection URL for other containers on the same network.
+func (n *NatsService) InternalURL() string {
+	return "nats://" + n.InternalAddr()
+}
+
 // NewNats creates a new NATS test helper.
-func NewNats(t *testing.T, ctx context.Context, settings ...option) *Bochka[*NatsService] {
+func NewNats(t testing.TB, ctx context.Context, settings ...option) *Bochka[*NatsService] {
+	t = reporter(t)
+
 	opts := options{
 		// default settings
-		image:   "docker.io/library/nats",
-		version: "2-alpine",
-		port:    natsPort,
+		image:          "docker.io/library/nats",
+		version:        "2-alpine",
+		port:           natsPort,
+		startupTimeout: 30 * time.Second,
 	}
 
 	opts.applyOptions(settings)
 
 	network := opts.network
-	if network == nil {
+	ownsNetwork := network == nil
+	if ownsNetwork {
 		var err error
 		network, err = NewNetwork(ctx)
 		if err != nil {
@@ -144,21 +158,8 @@ func NewNats(t *testing.T, ctx context.Context, settings ...option) *Bochka[*Nat
 
 	service := &NatsService{
 		network: network,
-		config: ContainerConfig{
-			Image:    opts.image,
-			Version:  opts.version,
-			HostPort: opts.port,
-			EnvVars:  opts.extraEnvVars,
-		},
-	}
-
-	bochka := &Bochka[*NatsService]{
-		t:       t,
-		options: opts,
-		Context: ctx,
-		network: network,
-		service: service,
+		config:  opts.containerConfig(t, natsHostAlias),
 	}
 
-	return bochka
+	return newBochka(t, ctx, opts, network, ownsNetwork, service)
 }
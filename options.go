@@ -1,31 +1,75 @@
 package bochka
 
 import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
 	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
 )
 
 type options struct {
-	network      *testcontainers.DockerNetwork
-	extraEnvVars map[string]string
-	image        string
-	version      string
-	port         string // Host port for container
+	network          *testcontainers.DockerNetwork
+	environment      *Environment
+	dependsOn        []ContainerService
+	extraEnvVars     map[string]string
+	image            string
+	version          string
+	customImage      bool   // the image was set by WithCustomImage
+	port             string // Host port for container
+	noCleanup        bool   // do not register t.Cleanup in the constructor
+	waitStrategy     wait.Strategy
+	additionalWaits  []wait.Strategy
+	startupTimeout   time.Duration
+	logStream        bool
+	logFilters       []LogFilter
+	logDir           string // directory for log artifacts, disabled if empty
+	logOnFailureOnly bool
+	logArtifacts     bool // the log artifacts were set by WithLogArtifacts
+	postgres         postgresOptions
+	pgbouncer        pgbouncerOptions
+	redis            redisOptions
 }
 
 type option func(*options)
 
 func (o *options) applyOptions(opts []option) {
 	o.extraEnvVars = make(map[string]string)
+	o.logDir = os.Getenv(LogDirEnv)
+	o.logOnFailureOnly, _ = strconv.ParseBool(os.Getenv(LogOnFailureOnlyEnv))
 	for _, opt := range opts {
 		opt(o)
 	}
 }
 
+// containerConfig returns the common container configuration built from the options.
+func (o *options) containerConfig(t testing.TB, alias string) ContainerConfig {
+	config := ContainerConfig{
+		Image:           o.image,
+		Version:         o.version,
+		NetworkAlias:    alias,
+		HostPort:        o.port,
+		EnvVars:         o.extraEnvVars,
+		WaitingFor:      o.waitStrategy,
+		AdditionalWaits: o.additionalWaits,
+		StartupTimeout:  o.startupTimeout,
+	}
+
+	if o.logStream {
+		config.LogConsumers = append(config.LogConsumers, newTestLogConsumer(t, alias, o.logFilters))
+	}
+
+	return config
+}
+
 // WithCustomImage sets a custom Docker image and version for the container.
 func WithCustomImage(image, version string) option {
 	return func(opt *options) {
 		opt.image = image
 		opt.version = version
+		opt.customImage = true
 	}
 }
 
@@ -36,6 +80,24 @@ func WithNetwork(network *testcontainers.DockerNetwork) option {
 	}
 }
 
+// WithEnvironment adds the container to the environment. The container joins the environment network
+// and is started and closed together with the other services of the environment, so WithoutCleanup
+// has no effect. WithLogArtifacts passed to the container takes precedence over the environment one.
+func WithEnvironment(env *Environment) option {
+	return func(opt *options) {
+		opt.environment = env
+		opt.network = env.network
+	}
+}
+
+// WithDependsOn makes the environment start the container only after the given services are ready.
+// It has effect only together with WithEnvironment.
+func WithDependsOn(services ...ContainerService) option {
+	return func(opt *options) {
+		opt.dependsOn = append(opt.dependsOn, services...)
+	}
+}
+
 // WithPort sets the host port for the container port binding.
 func WithPort(port string) option {
 	return func(opt *options) {
@@ -52,3 +114,53 @@ func WithEnvVars(vars map[string]string) option {
 		}
 	}
 }
+
+// WithoutCleanup disables the automatic teardown registered via t.Cleanup.
+// The caller becomes responsible for calling Close and removing the network.
+func WithoutCleanup() option {
+	return func(opt *options) {
+		opt.noCleanup = true
+	}
+}
+
+// WithWaitStrategy replaces the default readiness check of the service.
+func WithWaitStrategy(strategy wait.Strategy) option {
+	return func(opt *options) {
+		opt.waitStrategy = strategy
+	}
+}
+
+// WithAdditionalWait adds readiness checks on top of the default or the replaced one.
+// Multiple calls to WithAdditionalWait will merge the checks.
+func WithAdditionalWait(strategies ...wait.Strategy) option {
+	return func(opt *options) {
+		opt.additionalWaits = append(opt.additionalWaits, strategies...)
+	}
+}
+
+// WithStartupTimeout limits the time the container has to become ready.
+func WithStartupTimeout(timeout time.Duration) option {
+	return func(opt *options) {
+		opt.startupTimeout = timeout
+	}
+}
+
+// WithLogStream forwards the container output to the test log while the container is running.
+// Each line is prefixed with the host alias and forwarded only if it passes all filters.
+func WithLogStream(filters ...LogFilter) option {
+	return func(opt *options) {
+		opt.logStream = true
+		opt.logFilters = append(opt.logFilters, filters...)
+	}
+}
+
+// WithLogArtifacts writes the full container log to <dir>/<TestName>/<alias>.log when the container
+// is closed, always or only if the test failed. It overrides the BOCHKA_LOG_DIR and
+// BOCHKA_LOG_ON_FAILURE_ONLY environment variables.
+func WithLogArtifacts(dir string, onFailureOnly bool) option {
+	return func(opt *options) {
+		opt.logDir = dir
+		opt.logOnFailureOnly = onFailureOnly
+		opt.logArtifacts = true
+	}
+}
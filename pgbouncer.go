@@ -0,0 +1,229 @@
+package bochka
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strconv"
+	"testing"
+
+	faststrconv "github.com/kaatinga/strconv"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/network"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	pgbouncerHostAlias = "pgbouncer"
+	pgbouncerPort      = "6432"
+)
+
+var (
+	pgbouncerExposedPort network.Port
+)
+
+// PoolMode is the PgBouncer pooling mode.
+type PoolMode string
+
+const (
+	PoolModeSession     PoolMode = "session"     // a server connection is held for the client session
+	PoolModeTransaction PoolMode = "transaction" // a server connection is held for a transaction
+	PoolModeStatement   PoolMode = "statement"   // a server connection is held for a statement
+)
+
+// pgbouncerOptions holds the settings specific to NewPgBouncer. Other constructors ignore them.
+type pgbouncerOptions struct {
+	poolMode PoolMode
+	poolSize int
+}
+
+// WithPoolMode sets the PgBouncer pooling mode, transaction by default.
+func WithPoolMode(mode PoolMode) option {
+	return func(opt *options) {
+		opt.pgbouncer.poolMode = mode
+	}
+}
+
+// WithPoolSize sets the number of server connections PgBouncer opens per user and database.
+func WithPoolSize(size int) option {
+	return func(opt *options) {
+		opt.pgbouncer.poolSize = size
+	}
+}
+
+// PgBouncerService implements ContainerService for PgBouncer in front of a PostgresService.
+type PgBouncerService struct {
+	Container testcontainers.Container
+	network   *testcontainers.DockerNetwork
+	config    ContainerConfig
+	postgres  *PostgresService
+	opts      pgbouncerOptions
+	host      string
+	port      uint16
+}
+
+// Start starts the PgBouncer container and sets up connection details. Returns error on failure.
+// The PostgreSQL service must be started first.
+func (b *PgBouncerService) Start(ctx context.Context) error {
+	envVars := map[string]string{
+		"DB_HOST":     b.postgres.HostAlias(),
+		"DB_PORT":     postgresPort,
+		"DB_USER":     b.postgres.User(),
+		"DB_PASSWORD": b.postgres.Password(),
+		"DB_NAME":     b.postgres.DBName(),
+		"AUTH_TYPE":   "scram-sha-256",
+		"LISTEN_PORT": pgbouncerPort,
+		"POOL_MODE":   string(b.opts.poolMode),
+	}
+
+	if b.opts.poolSize > 0 {
+		envVars["DEFAULT_POOL_SIZE"] = strconv.Itoa(b.opts.poolSize)
+	}
+
+	for env, val := range b.config.EnvVars {
+		envVars[env] = val
+	}
+
+	containerReq := testcontainers.ContainerRequest{
+		Image:        b.config.Image + ":" + b.config.Version,
+		ExposedPorts: []string{pgbouncerExposedPort.String()},
+		Env:          envVars,
+		WaitingFor: b.config.waitStrategy(
+			wait.ForLog("process up"),
+			wait.ForListeningPort(pgbouncerExposedPort.String()),
+		),
+		Networks: []string{b.network.Name},
+		NetworkAliases: map[string][]string{
+			b.network.Name: {pgbouncerHostAlias},
+		},
+		HostConfigModifier: func(hostConfig *container.HostConfig) {
+			hostConfig.PortBindings = network.PortMap{
+				pgbouncerExposedPort: {{HostIP: AnyIP, HostPort: b.config.HostPort}},
+			}
+			hostConfig.AutoRemove = true
+		},
+		LogConsumerCfg: b.config.logConsumerConfig(),
+	}
+
+	var err error
+	b.Container, err = startContainer(ctx, containerReq, pgbouncerHostAlias)
+	if err != nil {
+		return err
+	}
+
+	b.host, err = containerHost(ctx, b.Container, pgbouncerHostAlias, containerReq.Image)
+	if err != nil {
+		return err
+	}
+
+	b.port, err = mappedPort(ctx, b.Container, pgbouncerHostAlias, containerReq.Image, pgbouncerPort)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Close terminates the PgBouncer container.
+func (b *PgBouncerService) Close() error {
+	if b.Container == nil {
+		return nil
+	}
+
+	return b.Container.Terminate(context.Background())
+}
+
+// NetworkName returns the name of the Docker network used by the container.
+func (b *PgBouncerService) NetworkName() string {
+	return b.network.Name
+}
+
+// Host returns the host address of the PgBouncer container.
+func (b *PgBouncerService) Host() string {
+	return b.host
+}
+
+// Port returns the mapped port of the PgBouncer container.
+func (b *PgBouncerService) Port() uint16 {
+	return b.port
+}
+
+// HostAlias returns the network alias for the PgBouncer container.
+func (b *PgBouncerService) HostAlias() string {
+	return pgbouncerHostAlias
+}
+
+// GetContainer returns the underlying container service.
+func (b *PgBouncerService) GetContainer() testcontainers.Container {
+	return b.Container
+}
+
+// PoolMode returns the pooling mode of PgBouncer.
+func (b *PgBouncerService) PoolMode() PoolMode {
+	return b.opts.poolMode
+}
+
+// DSN returns the connection URL of the PostgreSQL database via PgBouncer.
+func (b *PgBouncerService) DSN() string {
+	return b.dsn(net.JoinHostPort(b.Host(), faststrconv.Uint162String(b.Port())))
+}
+
+// InternalAddr returns alias:port for connections from other containers on the same network.
+func (b *PgBouncerService) InternalAddr() string {
+	return net.JoinHostPort(pgbouncerHostAlias, pgbouncerPort)
+}
+
+// InternalDSN returns the connection URL via PgBouncer for other containers on the same network.
+func (b *PgBouncerService) InternalDSN() string {
+	return b.dsn(b.InternalAddr())
+}
+
+// dsn returns the connection URL of the PostgreSQL database at the PgBouncer address.
+func (b *PgBouncerService) dsn(addr string) string {
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(b.postgres.User(), b.postgres.Password()),
+		Host:   addr,
+		Path:   "/" + b.postgres.DBName(),
+	}
+
+	return dsn.String()
+}
+
+// NewPgBouncer creates a PgBouncer test helper in front of the PostgreSQL service. PgBouncer joins
+// the network of the service unless WithNetwork or WithEnvironment is passed; in an environment it
+// starts after the service. The default pool mode is transaction.
+func NewPgBouncer(t testing.TB, ctx context.Context, postgres *PostgresService, settings ...option) *Bochka[*PgBouncerService] {
+	t = reporter(t)
+
+	opts := options{
+		// default settings
+		image:   "edoburu/pgbouncer",
+		version: "v1.23.1-p2",
+		port:    "6433",
+		pgbouncer: pgbouncerOptions{
+			poolMode: PoolModeTransaction,
+		},
+	}
+
+	opts.applyOptions(settings)
+
+	if opts.environment != nil {
+		opts.dependsOn = append(opts.dependsOn, postgres)
+	}
+
+	network := opts.network
+	if network == nil {
+		network = postgres.network
+	}
+
+	service := &PgBouncerService{
+		network:  network,
+		config:   opts.containerConfig(t, pgbouncerHostAlias),
+		postgres: postgres,
+		opts:     opts.pgbouncer,
+	}
+
+	return newBochka(t, ctx, opts, network, false, service)
+}
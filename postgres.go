@@ -2,6 +2,13 @@ package bochka
 
 import (
 	"context"
+	"errors"
+	"maps"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 
 	faststrconv "github.com/kaatinga/strconv"
@@ -17,6 +24,7 @@ const (
 	postgresDBName    = "testdb"
 	postgresHostAlias = "postgres"
 	postgresPort      = "5432"
+	postgresDataDir   = "/var/lib/postgresql/data"
 )
 
 var (
@@ -30,22 +38,95 @@ type PostgresService struct {
 	config    ContainerConfig
 	host      string
 	port      uint16
+	user      string
+	password  string
+	dbName    string
+	alias     string
+	opts      postgresOptions
+	tls       *postgresTLS // generated certificates if TLS is enabled
+
+	statements *StatementRecorder // statements read from the log if the statement log is enabled
+
+	primary    *PostgresService // replication source if the service is a replica
+	replicated bool             // accepts replication connections from replicas
+
+	databasesMu sync.Mutex          // serializes copying of the databases
+	template    string              // template database for NewTestDatabase, created on the first call
+	snapshots   map[string]struct{} // names of the snapshots taken by Snapshot
 }
 
 // Start starts the PostgreSQL container and sets up connection details. Returns error on failure.
 func (p *PostgresService) Start(ctx context.Context) error {
 	envVars := map[string]string{
-		"POSTGRES_DB":       postgresDBName,
-		"POSTGRES_USER":     postgresLogin,
-		"POSTGRES_PASSWORD": postgresPassword,
+		"POSTGRES_DB":       p.dbName,
+		"POSTGRES_USER":     p.user,
+		"POSTGRES_PASSWORD": p.password,
 	}
 
 	for env, val := range p.config.EnvVars {
 		envVars[env] = val
 	}
 
+	image := p.config.Image + ":" + p.config.Version
+
+	var files []testcontainers.ContainerFile
+	if p.primary == nil {
+		// a replica is a copy of the primary, the scripts have already run there
+		var err error
+		files, err = initScriptFiles(p.opts.initScripts)
+		if err != nil {
+			return &StartError{Phase: PhaseCreate, Alias: p.alias, Image: image, Err: err}
+		}
+	}
+
+	if p.replicated {
+		files = append(files, postgresReplicationScript())
+	}
+
+	// shell commands run as root before the image entrypoint
+	var prelude []string
+	if p.opts.tls {
+		var err error
+		p.tls, err = newPostgresTLS(ctx, p.alias)
+		if err != nil {
+			return &StartError{Phase: PhaseCreate, Alias: p.alias, Image: image, Err: err}
+		}
+
+		files = append(files, p.tls.files()...)
+		prelude = append(prelude, postgresTLSInstall)
+	}
+
+	readyLog := "database system is ready to accept connections"
+	if p.primary != nil {
+		envVars[postgresPrimaryConnInfoEnv] = p.primaryConnInfo()
+		prelude = append(prelude, postgresReplicaInit)
+		readyLog = "database system is ready to accept read-only connections"
+	}
+
+	var tmpfs map[string]string
+	if p.opts.fastMode {
+		if _, ok := envVars["PGDATA"]; !ok {
+			envVars["PGDATA"] = postgresDataDir
+		}
+		tmpfs = map[string]string{envVars["PGDATA"]: "rw"}
+	}
+
+	var entrypoint []string
+	cmd := p.serverArgs()
+	if len(prelude) > 0 {
+		script := "set -e\n" + strings.Join(prelude, "\n") + "\nexec docker-entrypoint.sh \"$@\""
+		entrypoint = []string{"sh", "-c", script, "sh"}
+		if cmd == nil {
+			cmd = []string{"postgres"}
+		}
+	}
+
 	containerReq := testcontainers.ContainerRequest{
-		Image:        p.config.Image + ":" + p.config.Version,
+		Image:        image,
+		Entrypoint:   entrypoint,
+		Cmd:          cmd,
+		Tmpfs:        tmpfs,
+		Files:        files,
 		ExposedPorts: []string{"5432/tcp"},
 		HostConfigModifier: func(hostConfig *container.HostConfig) {
 			hostConfig.PortBindings = network.PortMap{
@@ -53,48 +134,90 @@ func (p *PostgresService) Start(ctx context.Context) error {
 			}
 			hostConfig.AutoRemove = true
 		},
-		WaitingFor: wait.ForAll(
-			wait.ForLog("database system is ready to accept connections"),
+		WaitingFor: p.config.waitStrategy(
+			wait.ForLog(readyLog),
 			wait.ForListeningPort("5432/tcp"),
 		),
 		Env:      envVars,
 		Networks: []string{p.network.Name},
 		NetworkAliases: map[string][]string{
-			p.network.Name: {postgresHostAlias},
+			p.network.Name: {p.alias},
 		},
+		LogConsumerCfg: p.config.logConsumerConfig(),
 	}
 
 	var err error
-	p.Container, err = testcontainers.GenericContainer(
-		ctx,
-		testcontainers.GenericContainerRequest{
-			ContainerRequest: containerReq,
-			Started:          true,
-		})
+	p.Container, err = startContainer(ctx, containerReq, p.alias)
 	if err != nil {
 		return err
 	}
 
-	p.host, err = p.Container.Host(ctx)
+	p.host, err = containerHost(ctx, p.Container, p.alias, containerReq.Image)
 	if err != nil {
 		return err
 	}
 
-	mappedPort, err := p.Container.MappedPort(ctx, "5432")
+	p.port, err = mappedPort(ctx, p.Container, p.alias, containerReq.Image, postgresPort)
 	if err != nil {
 		return err
 	}
-	p.port, err = faststrconv.GetUint16(mappedPort.Port())
-	if err != nil {
-		return err
+
+	if p.primary != nil {
+		// extensions and migrations are replicated from the primary
+		return nil
+	}
+
+	if len(p.opts.extensions) > 0 {
+		if err = p.createExtensions(ctx); err != nil {
+			return &StartError{Phase: PhaseSetup, Alias: p.alias, Image: containerReq.Image, Err: err}
+		}
+	}
+
+	if p.opts.migrations != nil {
+		if err = p.Migrate(ctx, p.opts.migrations); err != nil {
+			return &StartError{Phase: PhaseSetup, Alias: p.alias, Image: containerReq.Image, Err: err}
+		}
 	}
 
 	return nil
 }
 
-// Close terminates the PostgreSQL container.
+// serverArgs returns the postgres command with the configured server parameters,
+// or nil to keep the image default.
+func (p *PostgresService) serverArgs() []string {
+	config := make(map[string]string)
+	if p.opts.fastMode {
+		maps.Copy(config, fastModeConfig)
+	}
+	if p.opts.tls {
+		maps.Copy(config, postgresTLSConfig)
+	}
+	if p.opts.statementLog {
+		maps.Copy(config, statementLogConfig)
+	}
+	maps.Copy(config, p.opts.config)
+
+	if len(config) == 0 {
+		return nil
+	}
+
+	args := []string{"postgres"}
+	for _, key := range slices.Sorted(maps.Keys(config)) {
+		args = append(args, "-c", key+"="+config[key])
+	}
+
+	return args
+}
+
+// Close terminates the PostgreSQL container and removes the CA certificate file if TLS is enabled.
 func (p *PostgresService) Close() error {
-	return p.Container.Terminate(context.Background())
+	tlsErr := p.tls.remove()
+
+	if p.Container == nil {
+		return tlsErr
+	}
+
+	return errors.Join(p.Container.Terminate(context.Background()), tlsErr)
 }
 
 // NetworkName returns the name of the Docker network used by the container.
@@ -114,22 +237,22 @@ func (p *PostgresService) Port() uint16 {
 
 // HostAlias returns the network alias for the PostgreSQL container.
 func (p *PostgresService) HostAlias() string {
-	return postgresHostAlias
+	return p.alias
 }
 
 // User returns the username for the PostgreSQL instance.
 func (p *PostgresService) User() string {
-	return postgresLogin
+	return p.user
 }
 
 // Password returns the password for the PostgreSQL instance.
 func (p *PostgresService) Password() string {
-	return postgresPassword
+	return p.password
 }
 
 // DBName returns the database name for the PostgreSQL instance.
 func (p *PostgresService) DBName() string {
-	return postgresDBName
+	return p.dbName
 }
 
 // GetContainer returns the underlying container service
@@ -137,12 +260,76 @@ func (p *PostgresService) GetContainer() testcontainers.Container {
 	return p.Container
 }
 
+// DSN returns the connection URL for the PostgreSQL instance. If TLS is enabled, the URL
+// requires sslmode=verify-full with sslrootcert pointing to the generated CA certificate.
+// Parameters set by WithDSNParams are appended to the query.
 func (p *PostgresService) DSN() string {
-	return "postgres://" + p.User() + ":" + p.Password() + "@" + p.Host() + ":" + faststrconv.Uint162String(p.Port()) + "/" + p.DBName()
+	return p.dsn(p.hostAddr(), p.DBName())
+}
+
+// hostAddr returns host:port of the mapped port.
+func (p *PostgresService) hostAddr() string {
+	return net.JoinHostPort(p.Host(), faststrconv.Uint162String(p.Port()))
+}
+
+// InternalAddr returns alias:port for connections from other containers on the same network.
+func (p *PostgresService) InternalAddr() string {
+	return net.JoinHostPort(p.alias, postgresPort)
+}
+
+// InternalDSN returns the connection URL for other containers on the same network. It carries
+// the same parameters as DSN; with TLS, sslrootcert refers to the CA file on the host, so mount
+// CACertPEM at that path or override sslrootcert via WithDSNParams.
+func (p *PostgresService) InternalDSN() string {
+	return p.dsn(p.InternalAddr(), p.DBName())
+}
+
+// dsn returns the connection URL for the database at the address.
+func (p *PostgresService) dsn(addr, dbName string) string {
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(p.User(), p.Password()),
+		Host:   addr,
+		Path:   "/" + dbName,
+	}
+
+	query := url.Values{}
+	if p.tls != nil {
+		query.Set("sslmode", "verify-full")
+		query.Set("sslrootcert", p.tls.rootCertFile)
+	}
+	for key, value := range p.opts.dsnParams {
+		query.Set(key, value)
+	}
+	dsn.RawQuery = query.Encode()
+
+	return dsn.String()
 }
 
 // NewPostgres creates a new PostgreSQL test helper.
-func NewPostgres(t *testing.T, ctx context.Context, settings ...option) *Bochka[*PostgresService] {
+func NewPostgres(t testing.TB, ctx context.Context, settings ...option) *Bochka[*PostgresService] {
+	t = reporter(t)
+
+	opts := postgresSettings(t, settings)
+
+	network := opts.network
+	ownsNetwork := network == nil
+	if ownsNetwork {
+		var err error
+		network, err = NewNetwork(ctx)
+		if err != nil {
+			t.Fatalf("failed to create network: %v", err)
+		}
+	}
+
+	service := newPostgresService(t, opts, network, postgresHostAlias)
+
+	return newBochka(t, ctx, opts, network, ownsNetwork, service)
+}
+
+// postgresSettings applies the settings on top of the PostgreSQL defaults and selects the image
+// providing the requested extensions.
+func postgresSettings(t testing.TB, settings []option) options {
 	opts := options{
 		// default settings
 		image:   "postgres",
@@ -152,32 +339,46 @@ func NewPostgres(t *testing.T, ctx context.Context, settings ...option) *Bochka[
 
 	opts.applyOptions(settings)
 
-	network := opts.network
-	if network == nil {
-		var err error
-		network, err = NewNetwork(ctx)
+	if !opts.customImage {
+		image, version, err := opts.postgres.extensionImage(opts.version)
 		if err != nil {
-			t.Fatalf("failed to create network: %v", err)
+			t.Fatalf("failed to select postgres image: %v", err)
+		}
+
+		if image != "" {
+			opts.image, opts.version = image, version
 		}
 	}
 
+	return opts
+}
+
+// newPostgresService creates a PostgreSQL service with the network alias on the network.
+func newPostgresService(t testing.TB, opts options, network *testcontainers.DockerNetwork, alias string) *PostgresService {
 	service := &PostgresService{
 		network: network,
-		config: ContainerConfig{
-			Image:    opts.image,
-			Version:  opts.version,
-			HostPort: opts.port,
-			EnvVars:  opts.extraEnvVars,
-		},
+		config:  opts.containerConfig(t, alias),
+		alias:   alias,
+		opts:    opts.postgres,
 	}
 
-	bochka := &Bochka[*PostgresService]{
-		t:       t,
-		options: opts,
-		Context: ctx,
-		network: network,
-		service: service,
+	service.user, service.password, service.dbName = opts.postgres.credentials()
+
+	if opts.postgres.statementLog {
+		service.statements = newStatementRecorder(service)
+		service.config.LogConsumers = append(service.config.LogConsumers, service.statements)
+	}
+
+	// keep the accessors truthful if the credentials are overridden via WithEnvVars
+	if user, ok := opts.extraEnvVars["POSTGRES_USER"]; ok {
+		service.user = user
+	}
+	if password, ok := opts.extraEnvVars["POSTGRES_PASSWORD"]; ok {
+		service.password = password
+	}
+	if dbName, ok := opts.extraEnvVars["POSTGRES_DB"]; ok {
+		service.dbName = dbName
 	}
 
-	return bochka
+	return service
 }
@@ -0,0 +1,55 @@
+// Package postgres provides ready-to-use connections to a bochka PostgreSQL container.
+// It lives in a separate module so the core bochka module does not depend on a driver.
+package postgres
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/kaatinga/bochka"
+)
+
+// OpenDB opens a database/sql connection to the service database using the pgx driver.
+// The connection is pinged before it is returned and closed on the test cleanup.
+func OpenDB(t testing.TB, service *bochka.PostgresService) *sql.DB {
+	t.Helper()
+
+	config, err := pgx.ParseConfig(service.DSN())
+	if err != nil {
+		t.Fatalf("failed to parse postgres DSN: %v", err)
+	}
+
+	db := stdlib.OpenDB(*config)
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("failed to close postgres connection: %v", err)
+		}
+	})
+
+	if err = db.PingContext(t.Context()); err != nil {
+		t.Fatalf("failed to ping postgres: %v", err)
+	}
+
+	return db
+}
+
+// Pool creates a pgx connection pool to the service database.
+// The pool is pinged before it is returned and closed on the test cleanup.
+func Pool(t testing.TB, service *bochka.PostgresService) *pgxpool.Pool {
+	t.Helper()
+
+	pool, err := pgxpool.New(t.Context(), service.DSN())
+	if err != nil {
+		t.Fatalf("failed to create postgres pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	if err = pool.Ping(t.Context()); err != nil {
+		t.Fatalf("failed to ping postgres: %v", err)
+	}
+
+	return pool
+}
@@ -0,0 +1,156 @@
+package bochka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// maintenanceDB is the database psql connects to when creating or dropping other databases.
+const maintenanceDB = "postgres"
+
+// maxIdentifierLength is the length in bytes of the longest identifier postgres keeps as is,
+// NAMEDATALEN-1. Longer identifiers are truncated.
+const maxIdentifierLength = 63
+
+// NewTestDatabase creates an isolated database for the test and returns its DSN. The database is
+// cloned from a template which is taken from the service database on the first call, so apply the
+// migrations before. Connections to the service database are terminated while the template is taken,
+// as a database cannot be copied while it is in use. The database is dropped on test cleanup.
+// It is safe to call from parallel tests.
+func (p *PostgresService) NewTestDatabase(t testing.TB) string {
+	t.Helper()
+
+	ctx := t.Context()
+
+	p.databasesMu.Lock()
+	defer p.databasesMu.Unlock()
+
+	if p.template == "" {
+		template := p.dbName + "_template"
+		err := p.withoutConnections(ctx, func() error {
+			_, err := p.psql(ctx, maintenanceDB,
+				"--command", "CREATE DATABASE "+quoteIdentifier(template)+" TEMPLATE "+quoteIdentifier(p.dbName),
+				// nobody must connect to the template, otherwise it cannot be cloned
+				"--command", "ALTER DATABASE "+quoteIdentifier(template)+" WITH IS_TEMPLATE true ALLOW_CONNECTIONS false",
+			)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("failed to create template database from %s: %v", p.dbName, err)
+		}
+		p.template = template
+	}
+
+	dbName := p.dbName + "_test_" + randomToken()
+	// databases cannot be cloned from the same template concurrently, hence the lock
+	_, err := p.psql(ctx, maintenanceDB, "--command", "CREATE DATABASE "+quoteIdentifier(dbName)+" TEMPLATE "+quoteIdentifier(p.template))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		// the test context is canceled by the time the cleanup runs
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if _, err := p.psql(ctx, maintenanceDB, "--command", "DROP DATABASE IF EXISTS "+quoteIdentifier(dbName)+" WITH (FORCE)"); err != nil {
+			t.Errorf("failed to drop test database %s: %v", dbName, err)
+		}
+	})
+
+	return p.dsn(p.hostAddr(), dbName)
+}
+
+// Snapshot saves the current state of the service database under the name, replacing an existing
+// snapshot with the same name. Connections to the service database are terminated, as a database
+// cannot be copied while it is in use. The snapshot is stored as the <db>_snapshot_<name> database,
+// so names making it longer than 63 bytes are rejected.
+func (p *PostgresService) Snapshot(ctx context.Context, name string) error {
+	snapshot, err := p.snapshotName(name)
+	if err != nil {
+		return err
+	}
+
+	p.databasesMu.Lock()
+	defer p.databasesMu.Unlock()
+
+	err = p.withoutConnections(ctx, func() error {
+		_, err := p.psql(ctx, maintenanceDB,
+			"--command", "DROP DATABASE IF EXISTS "+quoteIdentifier(snapshot),
+			"--command", "CREATE DATABASE "+quoteIdentifier(snapshot)+" TEMPLATE "+quoteIdentifier(p.dbName),
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to snapshot %s as %q: %w", p.dbName, name, err)
+	}
+
+	if p.snapshots == nil {
+		p.snapshots = make(map[string]struct{})
+	}
+	p.snapshots[name] = struct{}{}
+
+	return nil
+}
+
+// Restore brings the service database back to the state saved by Snapshot. Connections to the
+// service database are terminated. The snapshot is kept, so it can be restored again.
+func (p *PostgresService) Restore(ctx context.Context, name string) error {
+	p.databasesMu.Lock()
+	defer p.databasesMu.Unlock()
+
+	if _, ok := p.snapshots[name]; !ok {
+		return fmt.Errorf("snapshot %q does not exist", name)
+	}
+
+	// the name was validated by Snapshot
+	snapshot, _ := p.snapshotName(name)
+	err := p.withoutConnections(ctx, func() error {
+		_, err := p.psql(ctx, maintenanceDB,
+			"--command", "DROP DATABASE "+quoteIdentifier(p.dbName)+" WITH (FORCE)",
+			"--command", "CREATE DATABASE "+quoteIdentifier(p.dbName)+" TEMPLATE "+quoteIdentifier(snapshot),
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore %s from %q: %w", p.dbName, name, err)
+	}
+
+	return nil
+}
+
+// snapshotName returns the name of the database holding the snapshot. Returns error if the name
+// is too long for postgres to keep it distinct from other snapshots.
+func (p *PostgresService) snapshotName(name string) (string, error) {
+	snapshot := p.dbName + "_snapshot_" + name
+	if len(snapshot) > maxIdentifierLength {
+		return "", fmt.Errorf("snapshot name %q is too long: database name %q exceeds %d bytes", name, snapshot, maxIdentifierLength)
+	}
+
+	return snapshot, nil
+}
+
+// withoutConnections runs fn while the service database does not accept connections,
+// after terminating the existing ones.
+func (p *PostgresService) withoutConnections(ctx context.Context, fn func() error) (err error) {
+	db := quoteIdentifier(p.dbName)
+	_, err = p.psql(ctx, maintenanceDB,
+		"--command", "ALTER DATABASE "+db+" WITH ALLOW_CONNECTIONS false",
+		"--command", "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = "+quoteLiteral(p.dbName)+" AND pid <> pg_backend_pid()",
+	)
+
+	// the database may have been dropped and recreated by fn, which allows connections again
+	defer func() {
+		_, allowErr := p.psql(ctx, maintenanceDB, "--command", "ALTER DATABASE "+db+" WITH ALLOW_CONNECTIONS true")
+		err = errors.Join(err, allowErr)
+	}()
+
+	if err != nil {
+		return err
+	}
+
+	return fn()
+}
@@ -0,0 +1,27 @@
+package bochka
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_snapshotName(t *testing.T) {
+	p := &PostgresService{dbName: "test_db"}
+
+	snapshot, err := p.snapshotName("seeded")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshot != "test_db_snapshot_seeded" {
+		t.Errorf("unexpected snapshot name %q", snapshot)
+	}
+
+	// test_db_snapshot_ takes 17 bytes
+	if _, err = p.snapshotName(strings.Repeat("a", maxIdentifierLength-17)); err != nil {
+		t.Errorf("expected a 63-byte name to be accepted, got %v", err)
+	}
+
+	if _, err = p.snapshotName(strings.Repeat("a", maxIdentifierLength-16)); err == nil {
+		t.Error("expected a 64-byte name to be rejected")
+	}
+}
@@ -0,0 +1,50 @@
+package bochka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+)
+
+// errPostgresNotStarted is returned by the PostgresService methods that require a running container.
+var errPostgresNotStarted = errors.New("postgres container is not started")
+
+// psql runs psql inside the container against the database and returns its combined output.
+// Any SQL error aborts the script and is returned together with the output.
+func (p *PostgresService) psql(ctx context.Context, dbName string, args ...string) (string, error) {
+	if p.Container == nil {
+		return "", errPostgresNotStarted
+	}
+
+	cmd := append([]string{
+		"psql", "--no-psqlrc", "--quiet", "--echo-errors",
+		"--set", "ON_ERROR_STOP=1",
+		"--username", p.user,
+		"--dbname", dbName,
+	}, args...)
+
+	code, reader, err := p.Container.Exec(ctx, cmd, tcexec.Multiplexed(), tcexec.WithEnv([]string{"PGAPPNAME=" + postgresAppName}))
+	if err != nil {
+		return "", fmt.Errorf("failed to run psql: %w", err)
+	}
+
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read psql output: %w", err)
+	}
+
+	if code != 0 {
+		return string(output), fmt.Errorf("psql exited with code %d: %s", code, strings.TrimSpace(string(output)))
+	}
+
+	return string(output), nil
+}
+
+// quoteLiteral quotes the string as an SQL literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
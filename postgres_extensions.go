@@ -0,0 +1,87 @@
+package bochka
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// extensionImages are the images providing the extensions missing in the official image.
+var extensionImages = []struct {
+	image      string
+	version    func(major string) string
+	extensions []string
+}{
+	{
+		image:      "postgis/postgis",
+		version:    func(major string) string { return major + "-3.5" },
+		extensions: []string{"postgis", "postgis_raster", "postgis_topology", "postgis_sfcgal", "postgis_tiger_geocoder", "address_standardizer"},
+	},
+	{
+		image:      "pgvector/pgvector",
+		version:    func(major string) string { return "pg" + major },
+		extensions: []string{"vector"},
+	},
+}
+
+// extensionAliases maps the project names to the extension names.
+var extensionAliases = map[string]string{
+	"pgvector": "vector",
+}
+
+// extensionImage returns the image providing the requested extensions for the PostgreSQL version,
+// or an empty image if the official one is enough.
+func (o postgresOptions) extensionImage(version string) (image, imageVersion string, err error) {
+	major, _, _ := strings.Cut(version, ".")
+
+	for _, candidate := range extensionImages {
+		if !slices.ContainsFunc(o.extensions, func(extension string) bool {
+			return slices.Contains(candidate.extensions, extension)
+		}) {
+			continue
+		}
+
+		if image != "" {
+			return "", "", fmt.Errorf("no image provides extensions from both %s and %s, build one and pass it via WithCustomImage", image, candidate.image)
+		}
+
+		image, imageVersion = candidate.image, candidate.version(major)
+	}
+
+	return image, imageVersion, nil
+}
+
+// createExtensions creates the requested extensions in the service database.
+func (p *PostgresService) createExtensions(ctx context.Context) error {
+	literals := make([]string, 0, len(p.opts.extensions))
+	for _, extension := range p.opts.extensions {
+		literals = append(literals, quoteLiteral(extension))
+	}
+
+	output, err := p.psql(ctx, p.dbName, "--tuples-only", "--no-align", "--command",
+		"SELECT name FROM pg_available_extensions WHERE name IN ("+strings.Join(literals, ", ")+")")
+	if err != nil {
+		return fmt.Errorf("failed to list available extensions: %w", err)
+	}
+
+	available := strings.Fields(output)
+	var missing []string
+	for _, extension := range p.opts.extensions {
+		if !slices.Contains(available, extension) {
+			missing = append(missing, extension)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("extensions %s are not available in image %s:%s", strings.Join(missing, ", "), p.config.Image, p.config.Version)
+	}
+
+	for _, extension := range p.opts.extensions {
+		if _, err = p.psql(ctx, p.dbName, "--command", "CREATE EXTENSION IF NOT EXISTS "+quoteIdentifier(extension)+" CASCADE"); err != nil {
+			return fmt.Errorf("failed to create extension %s: %w", extension, err)
+		}
+	}
+
+	return nil
+}
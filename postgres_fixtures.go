@@ -0,0 +1,268 @@
+package bochka
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	initScriptsDir = "/docker-entrypoint-initdb.d"
+	fixturesDir    = "/tmp/bochka-fixtures"
+)
+
+// initScriptExtensions are the files the PostgreSQL image runs on the first start.
+var initScriptExtensions = []string{".sql", ".sql.gz", ".sh"}
+
+// initScriptSource is a set of init scripts added by WithInitScripts or WithInitScriptsFS.
+type initScriptSource struct {
+	fsys  fs.FS    // the scripts are the files in the root, if set
+	paths []string // files or directories with the scripts otherwise
+}
+
+// initScriptFiles reads the init scripts and returns them as container files. The files are
+// prefixed with their position, so the image runs them in the order they were added.
+func initScriptFiles(sources []initScriptSource) ([]testcontainers.ContainerFile, error) {
+	var files []testcontainers.ContainerFile
+	add := func(name string, content []byte) {
+		mode := int64(0o644)
+		if strings.HasSuffix(name, ".sh") {
+			mode = 0o755
+		}
+
+		files = append(files, testcontainers.ContainerFile{
+			Reader:            bytes.NewReader(content),
+			ContainerFilePath: path.Join(initScriptsDir, fmt.Sprintf("%03d_%s", len(files), name)),
+			FileMode:          mode,
+		})
+	}
+
+	for _, source := range sources {
+		if source.fsys != nil {
+			if err := readInitScripts(source.fsys, add); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		for _, scriptPath := range source.paths {
+			info, err := os.Stat(scriptPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read init script: %w", err)
+			}
+
+			if info.IsDir() {
+				if err := readInitScripts(os.DirFS(scriptPath), add); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			if !isInitScript(scriptPath) {
+				return nil, fmt.Errorf("unsupported init script %s, expected one of %v", scriptPath, initScriptExtensions)
+			}
+
+			content, err := os.ReadFile(scriptPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read init script: %w", err)
+			}
+			add(filepath.Base(scriptPath), content)
+		}
+	}
+
+	return files, nil
+}
+
+// readInitScripts passes the init scripts from the root of the file system to add in name order.
+func readInitScripts(fsys fs.FS, add func(name string, content []byte)) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return fmt.Errorf("failed to list init scripts: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !isInitScript(entry.Name()) {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read init script: %w", err)
+		}
+		add(entry.Name(), content)
+	}
+
+	return nil
+}
+
+func isInitScript(name string) bool {
+	return slices.ContainsFunc(initScriptExtensions, func(extension string) bool {
+		return strings.HasSuffix(name, extension)
+	})
+}
+
+// LoadFixtures loads rows from CSV and JSON files into the tables named after the files, e.g.
+// "users.csv" or "public.users.json" into the users table. CSV files must have a header row with
+// the column names; JSON files must contain an array of objects keyed by the column names.
+// The columns missing from a JSON object get their default values. The files are loaded in the
+// given order, or in name order if no files are given.
+func (p *PostgresService) LoadFixtures(ctx context.Context, fixtures fs.FS, files ...string) error {
+	if len(files) == 0 {
+		entries, err := fs.ReadDir(fixtures, ".")
+		if err != nil {
+			return fmt.Errorf("failed to list fixtures: %w", err)
+		}
+
+		for _, entry := range entries {
+			if extension := path.Ext(entry.Name()); !entry.IsDir() && (extension == ".csv" || extension == ".json") {
+				files = append(files, entry.Name())
+			}
+		}
+	}
+
+	for _, file := range files {
+		if err := p.loadFixture(ctx, fixtures, file); err != nil {
+			return fmt.Errorf("failed to load fixture %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *PostgresService) loadFixture(ctx context.Context, fixtures fs.FS, file string) error {
+	if p.Container == nil {
+		return errPostgresNotStarted
+	}
+
+	content, err := fs.ReadFile(fixtures, file)
+	if err != nil {
+		return err
+	}
+
+	extension := path.Ext(file)
+	table := quoteQualifiedIdentifier(strings.TrimSuffix(path.Base(file), extension))
+	containerPath := path.Join(fixturesDir, path.Base(file))
+
+	var statements []string
+	switch extension {
+	case ".csv":
+		columns, err := csv.NewReader(bytes.NewReader(content)).Read()
+		if err != nil {
+			return fmt.Errorf("failed to read the header: %w", err)
+		}
+
+		if err = p.copyFixture(ctx, content, containerPath); err != nil {
+			return err
+		}
+
+		statements = append(statements, "COPY "+table+" ("+columnList(columns)+") FROM "+quoteLiteral(containerPath)+" WITH (FORMAT csv, HEADER true)")
+	case ".json":
+		var rows []map[string]json.RawMessage
+		if err = json.Unmarshal(content, &rows); err != nil {
+			return fmt.Errorf("expected an array of objects: %w", err)
+		}
+
+		for i, group := range groupFixtureRows(rows) {
+			if len(group.columns) == 0 {
+				for range group.rows {
+					statements = append(statements, "INSERT INTO "+table+" DEFAULT VALUES")
+				}
+				continue
+			}
+
+			groupContent, err := json.Marshal(group.rows)
+			if err != nil {
+				return fmt.Errorf("failed to encode rows: %w", err)
+			}
+
+			groupPath := containerPath + "." + strconv.Itoa(i)
+			if err = p.copyFixture(ctx, groupContent, groupPath); err != nil {
+				return err
+			}
+
+			columns := columnList(group.columns)
+			statements = append(statements, "INSERT INTO "+table+" ("+columns+") SELECT "+columns+
+				" FROM json_populate_recordset(NULL::"+table+", pg_read_file("+quoteLiteral(groupPath)+")::json)")
+		}
+	default:
+		return fmt.Errorf("unsupported fixture format %q, expected .csv or .json", extension)
+	}
+
+	if len(statements) == 0 {
+		return nil
+	}
+
+	// the statements of a single command run in one transaction
+	_, err = p.psql(ctx, p.dbName, "--command", strings.Join(statements, ";\n"))
+	return err
+}
+
+// copyFixture copies the fixture content into the container. The server process reads the file,
+// so it must be readable by the postgres user.
+func (p *PostgresService) copyFixture(ctx context.Context, content []byte, containerPath string) error {
+	if err := p.Container.CopyToContainer(ctx, content, containerPath, 0o644); err != nil {
+		return fmt.Errorf("failed to copy the file: %w", err)
+	}
+
+	return nil
+}
+
+// fixtureGroup is a run of consecutive JSON fixture rows with the same keys.
+type fixtureGroup struct {
+	columns []string // sorted keys of the rows
+	rows    []map[string]json.RawMessage
+}
+
+// groupFixtureRows splits the rows into runs with the same keys, keeping the order, so that
+// every run is inserted with only the columns it sets and the others get their defaults.
+func groupFixtureRows(rows []map[string]json.RawMessage) []fixtureGroup {
+	var groups []fixtureGroup
+	for _, row := range rows {
+		columns := slices.Sorted(maps.Keys(row))
+		if last := len(groups) - 1; last >= 0 && slices.Equal(groups[last].columns, columns) {
+			groups[last].rows = append(groups[last].rows, row)
+			continue
+		}
+
+		groups = append(groups, fixtureGroup{columns: columns, rows: []map[string]json.RawMessage{row}})
+	}
+
+	return groups
+}
+
+// columnList returns the quoted, comma-separated column names.
+func columnList(columns []string) string {
+	quoted := make([]string, 0, len(columns))
+	for _, column := range columns {
+		quoted = append(quoted, quoteIdentifier(column))
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// quoteIdentifier quotes the string as an SQL identifier.
+func quoteIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// quoteQualifiedIdentifier quotes each part of a dot-separated name, e.g. schema.table.
+func quoteQualifiedIdentifier(s string) string {
+	parts := strings.Split(s, ".")
+	for i, part := range parts {
+		parts[i] = quoteIdentifier(part)
+	}
+
+	return strings.Join(parts, ".")
+}
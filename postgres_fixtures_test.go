@@ -0,0 +1,45 @@
+package bochka
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func Test_groupFixtureRows(t *testing.T) {
+	var rows []map[string]json.RawMessage
+	err := json.Unmarshal([]byte(`[
+		{"author_id": 1, "title": "War and Peace", "year": 1869},
+		{"year": 1877, "title": "Anna Karenina", "author_id": 1},
+		{"author_id": 2, "title": "The Cherry Orchard"},
+		{},
+		{"author_id": 1, "title": "Hadji Murat", "year": 1912}
+	]`), &rows)
+	if err != nil {
+		t.Fatalf("failed to decode rows: %v", err)
+	}
+
+	groups := groupFixtureRows(rows)
+
+	want := []struct {
+		columns []string
+		rows    int
+	}{
+		{[]string{"author_id", "title", "year"}, 2},
+		{[]string{"author_id", "title"}, 1},
+		{nil, 1},
+		{[]string{"author_id", "title", "year"}, 1},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("expected %d groups, got %d", len(want), len(groups))
+	}
+
+	for i, group := range groups {
+		if !slices.Equal(group.columns, want[i].columns) {
+			t.Errorf("group %d: expected columns %v, got %v", i, want[i].columns, group.columns)
+		}
+		if len(group.rows) != want[i].rows {
+			t.Errorf("group %d: expected %d rows, got %d", i, want[i].rows, len(group.rows))
+		}
+	}
+}
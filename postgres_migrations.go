@@ -0,0 +1,93 @@
+package bochka
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MigrationsTable is the table where the applied migration versions are recorded.
+	MigrationsTable = "bochka_schema_migrations"
+
+	migrationSuffix = ".up.sql"
+	migrationsDir   = "/tmp/bochka-migrations"
+)
+
+// Migrate applies the *.up.sql files from the root of the file system in version order.
+// The version is the file name without the suffix, e.g. "0001_create_users" for
+// "0001_create_users.up.sql"; numeric prefixes are compared as numbers. Each file is applied
+// in a single transaction and recorded in MigrationsTable, so already applied files are skipped.
+func (p *PostgresService) Migrate(ctx context.Context, migrations fs.FS) error {
+	files, err := fs.Glob(migrations, "*"+migrationSuffix)
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	slices.SortFunc(files, compareMigrations)
+
+	_, err = p.psql(ctx, p.dbName, "--command",
+		"CREATE TABLE IF NOT EXISTS "+MigrationsTable+" (version text PRIMARY KEY, applied_at timestamptz NOT NULL DEFAULT now())")
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	output, err := p.psql(ctx, p.dbName, "--tuples-only", "--no-align", "--command", "SELECT version FROM "+MigrationsTable)
+	if err != nil {
+		return fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	applied := strings.Fields(output)
+
+	for _, file := range files {
+		version := strings.TrimSuffix(file, migrationSuffix)
+		if slices.Contains(applied, version) {
+			continue
+		}
+
+		content, err := fs.ReadFile(migrations, file)
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", file, err)
+		}
+
+		containerPath := path.Join(migrationsDir, file)
+		if err := p.Container.CopyToContainer(ctx, content, containerPath, 0o644); err != nil {
+			return fmt.Errorf("failed to copy migration %s: %w", file, err)
+		}
+
+		// the failed statement is echoed by psql and ends up in the error
+		_, err = p.psql(ctx, p.dbName, "--single-transaction",
+			"--file", containerPath,
+			"--command", "INSERT INTO "+MigrationsTable+" (version) VALUES ("+quoteLiteral(version)+")",
+		)
+		if err != nil {
+			return fmt.Errorf("migration %s failed: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+// compareMigrations orders migration files by the numeric version prefix, then by name.
+func compareMigrations(a, b string) int {
+	aVersion, aErr := strconv.ParseUint(migrationPrefix(a), 10, 64)
+	bVersion, bErr := strconv.ParseUint(migrationPrefix(b), 10, 64)
+	if aErr == nil && bErr == nil && aVersion != bVersion {
+		return cmp.Compare(aVersion, bVersion)
+	}
+
+	return strings.Compare(a, b)
+}
+
+// migrationPrefix returns the part of the file name before the first underscore or dot.
+func migrationPrefix(file string) string {
+	if i := strings.IndexAny(file, "_."); i >= 0 {
+		return file[:i]
+	}
+
+	return file
+}
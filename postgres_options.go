@@ -0,0 +1,187 @@
+package bochka
+
+import (
+	"crypto/rand"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// postgresOptions holds the settings specific to NewPostgres. Other constructors ignore them.
+type postgresOptions struct {
+	user              string
+	password          string
+	dbName            string
+	randomCredentials bool
+	migrations        fs.FS // applied after the container is ready
+	initScripts       []initScriptSource
+	fastMode          bool
+	config            map[string]string // server parameters passed as -c key=value
+	extensions        []string
+	tls               bool
+	dsnParams         map[string]string // query parameters of DSN and InternalDSN
+	statementLog      bool
+}
+
+// fastModeConfig trades durability for speed, which tests do not need.
+var fastModeConfig = map[string]string{
+	"fsync":              "off",
+	"synchronous_commit": "off",
+	"full_page_writes":   "off",
+}
+
+// credentials returns the user, password and database name for the container.
+// Explicitly set values take precedence over the random ones, which take precedence over the defaults.
+func (o postgresOptions) credentials() (user, password, dbName string) {
+	user, password, dbName = postgresLogin, postgresPassword, postgresDBName
+	if o.randomCredentials {
+		user = "user_" + randomToken()
+		password = rand.Text()
+		dbName = "db_" + randomToken()
+	}
+
+	if o.user != "" {
+		user = o.user
+	}
+
+	if o.password != "" {
+		password = o.password
+	}
+
+	if o.dbName != "" {
+		dbName = o.dbName
+	}
+
+	return user, password, dbName
+}
+
+// randomToken returns a short random lowercase identifier suitable for PostgreSQL names.
+func randomToken() string {
+	return strings.ToLower(rand.Text()[:10])
+}
+
+// WithPostgresUser sets the PostgreSQL superuser name.
+func WithPostgresUser(user string) option {
+	return func(opt *options) {
+		opt.postgres.user = user
+	}
+}
+
+// WithPostgresPassword sets the PostgreSQL superuser password.
+func WithPostgresPassword(password string) option {
+	return func(opt *options) {
+		opt.postgres.password = password
+	}
+}
+
+// WithPostgresDB sets the name of the database created on startup.
+func WithPostgresDB(dbName string) option {
+	return func(opt *options) {
+		opt.postgres.dbName = dbName
+	}
+}
+
+// WithRandomPostgresCredentials generates a random user, password and database name for each run.
+// Values set by WithPostgresUser, WithPostgresPassword and WithPostgresDB are kept.
+func WithRandomPostgresCredentials() option {
+	return func(opt *options) {
+		opt.postgres.randomCredentials = true
+	}
+}
+
+// WithMigrations applies the *.up.sql files from the file system, e.g. an embed.FS, after the container
+// is ready, so the schema is in place when Start returns. See PostgresService.Migrate.
+func WithMigrations(migrations fs.FS) option {
+	return func(opt *options) {
+		opt.postgres.migrations = migrations
+	}
+}
+
+// WithMigrationsDir applies the *.up.sql files from the directory after the container is ready.
+func WithMigrationsDir(dir string) option {
+	return WithMigrations(os.DirFS(dir))
+}
+
+// WithInitScripts copies .sql, .sql.gz and .sh files into /docker-entrypoint-initdb.d, where the
+// image runs them on the first start. Directories are expanded to the scripts they contain.
+// The scripts run in the order they were added.
+func WithInitScripts(paths ...string) option {
+	return func(opt *options) {
+		opt.postgres.initScripts = append(opt.postgres.initScripts, initScriptSource{paths: paths})
+	}
+}
+
+// WithInitScriptsFS copies .sql, .sql.gz and .sh files from the root of the file system, e.g. an
+// embed.FS, into /docker-entrypoint-initdb.d, where the image runs them on the first start.
+func WithInitScriptsFS(scripts fs.FS) option {
+	return func(opt *options) {
+		opt.postgres.initScripts = append(opt.postgres.initScripts, initScriptSource{fsys: scripts})
+	}
+}
+
+// WithFastMode starts PostgreSQL without durability: fsync, synchronous_commit and full_page_writes
+// are off and the data directory is on tmpfs. Parameters set by WithPostgresConfig take precedence.
+func WithFastMode() option {
+	return func(opt *options) {
+		opt.postgres.fastMode = true
+	}
+}
+
+// WithPostgresConfig passes server parameters to postgres as -c key=value.
+// Multiple calls to WithPostgresConfig will merge the parameters.
+func WithPostgresConfig(config map[string]string) option {
+	return func(opt *options) {
+		if opt.postgres.config == nil {
+			opt.postgres.config = make(map[string]string, len(config))
+		}
+		maps.Copy(opt.postgres.config, config)
+	}
+}
+
+// WithExtensions creates the extensions in the database before Start returns. If an extension is
+// not shipped with the official image, e.g. postgis or pgvector, a compatible image is selected
+// unless WithCustomImage is passed. Start fails if an extension is not available in the image.
+func WithExtensions(extensions ...string) option {
+	return func(opt *options) {
+		for _, extension := range extensions {
+			if name, ok := extensionAliases[extension]; ok {
+				extension = name
+			}
+
+			if !slices.Contains(opt.postgres.extensions, extension) {
+				opt.postgres.extensions = append(opt.postgres.extensions, extension)
+			}
+		}
+	}
+}
+
+// WithTLS enables TLS with a throwaway CA and a server certificate valid for the host alias,
+// localhost and the Docker daemon host. DSN requires sslmode=verify-full against the CA.
+func WithTLS() option {
+	return func(opt *options) {
+		opt.postgres.tls = true
+	}
+}
+
+// WithDSNParams adds query parameters, e.g. sslmode, application_name or search_path, to the URLs
+// returned by DSN, InternalDSN and NewTestDatabase. They take precedence over the TLS parameters.
+// Multiple calls to WithDSNParams will merge the parameters.
+func WithDSNParams(params map[string]string) option {
+	return func(opt *options) {
+		if opt.postgres.dsnParams == nil {
+			opt.postgres.dsnParams = make(map[string]string, len(params))
+		}
+		maps.Copy(opt.postgres.dsnParams, params)
+	}
+}
+
+// WithStatementLog makes the server log every statement with its duration and, via auto_explain,
+// its plan, and records them for PostgresService.Statements. It sets log_min_duration_statement,
+// log_line_prefix and shared_preload_libraries; overriding them via WithPostgresConfig breaks the recording.
+func WithStatementLog() option {
+	return func(opt *options) {
+		opt.postgres.statementLog = true
+	}
+}
@@ -0,0 +1,227 @@
+package bochka
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	postgresReplicaAliasPrefix = "postgres-replica-"
+	postgresPrimaryConnInfoEnv = "BOCHKA_PRIMARY_CONNINFO"
+	replicaCatchUpInterval     = 100 * time.Millisecond
+)
+
+// postgresReplicaInit copies the primary into the empty data directory and configures the
+// streaming replication, so the image entrypoint starts a hot standby.
+const postgresReplicaInit = `if [ ! -s "$PGDATA/PG_VERSION" ]; then
+	run=gosu
+	command -v gosu >/dev/null || run=su-exec
+	mkdir -p "$PGDATA"
+	chown postgres:postgres "$PGDATA"
+	chmod 0700 "$PGDATA"
+	$run postgres pg_basebackup --pgdata="$PGDATA" --write-recovery-conf --wal-method=stream --checkpoint=fast --dbname="$` + postgresPrimaryConnInfoEnv + `"
+fi`
+
+// postgresReplicationScript returns the init script allowing replication connections with a password.
+func postgresReplicationScript() testcontainers.ContainerFile {
+	script := `echo "host replication all all scram-sha-256" >> "$PGDATA/pg_hba.conf"` + "\n"
+
+	return testcontainers.ContainerFile{
+		Reader:            bytes.NewReader([]byte(script)),
+		ContainerFilePath: initScriptsDir + "/bochka_replication.sh",
+		FileMode:          0o755,
+	}
+}
+
+// primaryConnInfo returns the libpq connection string the replica uses to stream from the primary.
+func (p *PostgresService) primaryConnInfo() string {
+	params := []struct{ key, value string }{
+		{"host", p.primary.alias},
+		{"port", postgresPort},
+		{"user", p.primary.user},
+		{"password", p.primary.password},
+		{"application_name", p.alias},
+	}
+
+	pairs := make([]string, 0, len(params))
+	for _, param := range params {
+		value := strings.ReplaceAll(strings.ReplaceAll(param.value, `\`, `\\`), `'`, `\'`)
+		pairs = append(pairs, param.key+"='"+value+"'")
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// PostgresReplicaSet implements ContainerService for a PostgreSQL primary with hot standby replicas
+// attached via streaming replication.
+type PostgresReplicaSet struct {
+	primary  *PostgresService
+	replicas []*PostgresService
+}
+
+// Start starts the primary and then the replicas concurrently. Returns the joined errors on failure.
+func (s *PostgresReplicaSet) Start(ctx context.Context) error {
+	if err := s.primary.Start(ctx); err != nil {
+		return err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, replica := range s.replicas {
+		wg.Go(func() {
+			if err := replica.Start(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Close terminates the replicas and then the primary.
+func (s *PostgresReplicaSet) Close() error {
+	var errs []error
+	for _, replica := range slices.Backward(s.replicas) {
+		errs = append(errs, replica.Close())
+	}
+
+	return errors.Join(append(errs, s.primary.Close())...)
+}
+
+// HostAlias returns the network alias of the primary.
+func (s *PostgresReplicaSet) HostAlias() string {
+	return s.primary.HostAlias()
+}
+
+// GetContainer returns the container of the primary.
+func (s *PostgresReplicaSet) GetContainer() testcontainers.Container {
+	return s.primary.GetContainer()
+}
+
+// containerServices returns the primary and the replicas, so the logs of all of them are printed.
+func (s *PostgresReplicaSet) containerServices() []ContainerService {
+	services := []ContainerService{s.primary}
+	for _, replica := range s.replicas {
+		services = append(services, replica)
+	}
+
+	return services
+}
+
+// Primary returns the primary service.
+func (s *PostgresReplicaSet) Primary() *PostgresService {
+	return s.primary
+}
+
+// Replicas returns the replica services, aliased postgres-replica-1, postgres-replica-2 and so on.
+func (s *PostgresReplicaSet) Replicas() []*PostgresService {
+	return slices.Clone(s.replicas)
+}
+
+// WaitForReplicaCatchUp waits until every replica has replayed the WAL written by the primary
+// up to the moment of the call. Promoted replicas are skipped.
+func (s *PostgresReplicaSet) WaitForReplicaCatchUp(ctx context.Context) error {
+	output, err := s.primary.psql(ctx, s.primary.dbName, "--tuples-only", "--no-align", "--command", "SELECT pg_current_wal_lsn()")
+	if err != nil {
+		return fmt.Errorf("failed to get primary WAL position: %w", err)
+	}
+	lsn := strings.TrimSpace(output)
+
+	query := "SELECT NOT pg_is_in_recovery() OR pg_last_wal_replay_lsn() >= " + quoteLiteral(lsn) + "::pg_lsn"
+	for _, replica := range s.replicas {
+		for {
+			output, err = replica.psql(ctx, replica.dbName, "--tuples-only", "--no-align", "--command", query)
+			if err != nil {
+				return fmt.Errorf("failed to get %s WAL position: %w", replica.alias, err)
+			}
+
+			if strings.TrimSpace(output) == "t" {
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%s has not replayed WAL up to %s: %w", replica.alias, lsn, ctx.Err())
+			case <-time.After(replicaCatchUpInterval):
+			}
+		}
+	}
+
+	return nil
+}
+
+// Promote turns the replica into a primary accepting writes and waits until the promotion is
+// complete. The other replicas keep following the former primary.
+func (s *PostgresReplicaSet) Promote(ctx context.Context, replica *PostgresService) error {
+	if !slices.Contains(s.replicas, replica) {
+		return fmt.Errorf("%s is not a replica of %s", replica.HostAlias(), s.primary.alias)
+	}
+
+	output, err := replica.psql(ctx, replica.dbName, "--tuples-only", "--no-align", "--command", "SELECT pg_promote()")
+	if err != nil {
+		return fmt.Errorf("failed to promote %s: %w", replica.alias, err)
+	}
+
+	if strings.TrimSpace(output) != "t" {
+		return fmt.Errorf("%s was not promoted in time", replica.alias)
+	}
+
+	return nil
+}
+
+// NewPostgresReplicaSet creates a PostgreSQL primary with the number of hot standby replicas on a
+// shared network. The settings apply to every node, except WithPort, which applies to the primary;
+// the replicas get ports chosen by Docker. Init scripts, extensions and migrations run on the
+// primary and reach the replicas via replication.
+func NewPostgresReplicaSet(t testing.TB, ctx context.Context, replicas int, settings ...option) *Bochka[*PostgresReplicaSet] {
+	t = reporter(t)
+
+	if replicas < 1 {
+		t.Fatalf("postgres replica set requires at least one replica, got %d", replicas)
+	}
+
+	opts := postgresSettings(t, settings)
+
+	network := opts.network
+	ownsNetwork := network == nil
+	if ownsNetwork {
+		var err error
+		network, err = NewNetwork(ctx)
+		if err != nil {
+			t.Fatalf("failed to create network: %v", err)
+		}
+	}
+
+	set := &PostgresReplicaSet{
+		primary: newPostgresService(t, opts, network, postgresHostAlias),
+	}
+	set.primary.replicated = true
+
+	replicaOpts := opts
+	replicaOpts.port = ""
+	for i := range replicas {
+		replica := newPostgresService(t, replicaOpts, network, postgresReplicaAliasPrefix+strconv.Itoa(i+1))
+		// random credentials are generated per service, the replicas use the ones of the primary
+		replica.user, replica.password, replica.dbName = set.primary.user, set.primary.password, set.primary.dbName
+		replica.primary = set.primary
+		set.replicas = append(set.replicas, replica)
+	}
+
+	return newBochka(t, ctx, opts, network, ownsNetwork, set)
+}
@@ -0,0 +1,278 @@
+package bochka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// postgresAppName is the application name of the psql sessions run by bochka itself.
+// Their statements are not recorded.
+const postgresAppName = "bochka"
+
+// statementLogConfig makes postgres log every statement with its duration and plan.
+var statementLogConfig = map[string]string{
+	"log_min_duration_statement":    "0",
+	"log_line_prefix":               "%m|%p|%a|",
+	"shared_preload_libraries":      "auto_explain",
+	"auto_explain.log_min_duration": "0",
+	"auto_explain.log_format":       "json",
+}
+
+var (
+	// statementLogEntry matches a log entry, as formatted by statementLogConfig. The header is on
+	// the first line, the message may continue on the next ones.
+	statementLogEntry = regexp.MustCompile(`^(\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\.\d+ \S+)\|(\d+)\|([^|\n]*)\|(\w+):  ((?s:.*))$`)
+	// statementMessage matches the duration message of a simple or an extended protocol statement.
+	statementMessage = regexp.MustCompile(`(?s)^duration: ([\d.]+) ms  (?:statement|execute [^:]*): (.*)$`)
+	// planMessage matches the plan logged by auto_explain.
+	planMessage = regexp.MustCompile(`(?s)^duration: [\d.]+ ms  plan:\n(.*)$`)
+)
+
+// Statement is an SQL statement executed by the server.
+type Statement struct {
+	Time        time.Time     // when the statement finished
+	PID         int           // process ID of the backend
+	Application string        // application_name of the session
+	Duration    time.Duration // execution time reported by the server
+	Query       string
+	Plan        json.RawMessage // plan in the auto_explain JSON format, nil for utility statements
+}
+
+// UsesNode reports whether the plan contains a node of the type, e.g. "Seq Scan" or "Index Scan".
+func (s Statement) UsesNode(nodeType string) bool {
+	if len(s.Plan) == 0 {
+		return false
+	}
+
+	var node planNode
+	if err := json.Unmarshal(s.Plan, &node); err != nil {
+		return false
+	}
+
+	return node.uses(nodeType)
+}
+
+// planNode is the part of a plan node needed to walk the plan tree.
+type planNode struct {
+	NodeType string     `json:"Node Type"`
+	Plans    []planNode `json:"Plans"`
+}
+
+// uses reports whether the node or any of its children is of the type.
+func (n planNode) uses(nodeType string) bool {
+	return n.NodeType == nodeType || slices.ContainsFunc(n.Plans, func(child planNode) bool {
+		return child.uses(nodeType)
+	})
+}
+
+// Statements is a list of recorded statements.
+type Statements []Statement
+
+// Containing returns the statements whose query contains the substring.
+func (s Statements) Containing(substr string) Statements {
+	var matching Statements
+	for _, statement := range s {
+		if strings.Contains(statement.Query, substr) {
+			matching = append(matching, statement)
+		}
+	}
+
+	return matching
+}
+
+// UsingNode returns the statements whose plan contains a node of the type.
+func (s Statements) UsingNode(nodeType string) Statements {
+	var matching Statements
+	for _, statement := range s {
+		if statement.UsesNode(nodeType) {
+			matching = append(matching, statement)
+		}
+	}
+
+	return matching
+}
+
+// StatementRecorder collects the statements executed by the server from the container log.
+// The statements run by bochka itself, e.g. migrations, are not recorded.
+type StatementRecorder struct {
+	service *PostgresService
+
+	mu         sync.Mutex
+	partial    string   // incomplete last line of the previous chunk
+	entry      []string // lines of the entry being read
+	plans      map[int]json.RawMessage
+	statements Statements
+	markers    map[string]chan struct{} // sync markers awaited by Sync
+}
+
+// newStatementRecorder creates a recorder for the service.
+func newStatementRecorder(service *PostgresService) *StatementRecorder {
+	return &StatementRecorder{
+		service: service,
+		plans:   make(map[int]json.RawMessage),
+		markers: make(map[string]chan struct{}),
+	}
+}
+
+// Accept implements testcontainers.LogConsumer.
+func (r *StatementRecorder) Accept(log testcontainers.Log) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lines := strings.Split(r.partial+string(log.Content), "\n")
+	r.partial = lines[len(lines)-1]
+
+	for _, line := range lines[:len(lines)-1] {
+		if continuation, ok := strings.CutPrefix(line, "\t"); ok && len(r.entry) > 0 {
+			r.entry = append(r.entry, continuation)
+			continue
+		}
+
+		// the entry is complete only when the next one starts, as every line comes separately
+		r.flush()
+		r.entry = append(r.entry, line)
+	}
+
+	// nothing may be logged after the sync marker for a while, so the statements run by bochka,
+	// which are not recorded, are parsed without waiting for their continuation lines
+	if r.ownEntry() {
+		r.flush()
+	}
+}
+
+// ownEntry reports whether the entry being read was logged by a session of bochka itself.
+func (r *StatementRecorder) ownEntry() bool {
+	if len(r.entry) == 0 {
+		return false
+	}
+
+	match := statementLogEntry.FindStringSubmatch(r.entry[0])
+	return match != nil && match[3] == postgresAppName
+}
+
+// flush parses the entry being read.
+func (r *StatementRecorder) flush() {
+	if len(r.entry) == 0 {
+		return
+	}
+
+	text := strings.Join(r.entry, "\n")
+	r.entry = r.entry[:0]
+
+	match := statementLogEntry.FindStringSubmatch(text)
+	if match == nil || match[4] != "LOG" {
+		return
+	}
+
+	pid, _ := strconv.Atoi(match[2])
+	application, message := match[3], match[5]
+
+	if plan := planMessage.FindStringSubmatch(message); plan != nil {
+		var explain struct {
+			Plan json.RawMessage `json:"Plan"`
+		}
+		if err := json.Unmarshal([]byte(plan[1]), &explain); err == nil {
+			r.plans[pid] = explain.Plan
+		}
+		return
+	}
+
+	statement := statementMessage.FindStringSubmatch(message)
+	if statement == nil {
+		return
+	}
+
+	// auto_explain logs the plan before the statement finishes
+	plan := r.plans[pid]
+	delete(r.plans, pid)
+
+	if application == postgresAppName {
+		for marker, done := range r.markers {
+			if strings.Contains(statement[2], marker) {
+				close(done)
+				delete(r.markers, marker)
+			}
+		}
+		return
+	}
+
+	finished, _ := time.Parse("2006-01-02 15:04:05.000 MST", match[1])
+	duration, _ := strconv.ParseFloat(statement[1], 64)
+
+	r.statements = append(r.statements, Statement{
+		Time:        finished,
+		PID:         pid,
+		Application: application,
+		Duration:    time.Duration(duration * float64(time.Millisecond)),
+		Query:       statement[2],
+		Plan:        plan,
+	})
+}
+
+// Sync waits until the statements executed so far have been read from the container log. The
+// entries logged before the sync statement are complete by then, as its entry starts after them.
+func (r *StatementRecorder) Sync(ctx context.Context) error {
+	marker := "bochka_sync_" + randomToken()
+	done := make(chan struct{})
+
+	r.mu.Lock()
+	r.markers[marker] = done
+	r.mu.Unlock()
+
+	if _, err := r.service.psql(ctx, maintenanceDB, "--command", "SELECT "+quoteLiteral(marker)); err != nil {
+		r.mu.Lock()
+		delete(r.markers, marker)
+		r.mu.Unlock()
+		return fmt.Errorf("failed to run sync statement: %w", err)
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		r.mu.Lock()
+		delete(r.markers, marker)
+		r.mu.Unlock()
+		return fmt.Errorf("statement log was not read in time: %w", ctx.Err())
+	}
+}
+
+// Statements returns the statements executed since the start or the last Reset.
+func (r *StatementRecorder) Statements(ctx context.Context) (Statements, error) {
+	if err := r.Sync(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.statements), nil
+}
+
+// Reset drops the statements executed so far, so the next call to Statements returns only the new ones.
+func (r *StatementRecorder) Reset(ctx context.Context) error {
+	if err := r.Sync(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.statements = nil
+
+	return nil
+}
+
+// Statements returns the recorder of the executed statements, or nil unless WithStatementLog is passed.
+func (p *PostgresService) Statements() *StatementRecorder {
+	return p.statements
+}
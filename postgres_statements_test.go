@@ -0,0 +1,94 @@
+package bochka
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// statementLog is a container log with a multi-line plan, a multi-line query, an extended protocol
+// statement with its parameters and a sync marker.
+const statementLog = `2026-10-16 12:00:00.100 UTC|42|app|LOG:  duration: 0.050 ms  plan:
+	{
+	  "Query Text": "SELECT *\nFROM users",
+	  "Plan": {
+	    "Node Type": "Seq Scan",
+	    "Relation Name": "users"
+	  }
+	}
+2026-10-16 12:00:00.123 UTC|42|app|LOG:  duration: 0.100 ms  statement: SELECT *
+	FROM users
+2026-10-16 12:00:00.200 UTC|43|app|LOG:  duration: 1.500 ms  execute stmtcache_1: SELECT name FROM users WHERE id = $1
+2026-10-16 12:00:00.200 UTC|43|app|DETAIL:  parameters: $1 = '1'
+2026-10-16 12:00:00.300 UTC|44|bochka|LOG:  duration: 0.010 ms  plan:
+	{
+	  "Query Text": "SELECT 'bochka_sync_test'",
+	  "Plan": {
+	    "Node Type": "Result"
+	  }
+	}
+2026-10-16 12:00:00.301 UTC|44|bochka|LOG:  duration: 0.020 ms  statement: SELECT 'bochka_sync_test'
+`
+
+func Test_StatementRecorderAccept(t *testing.T) {
+	lines := strings.SplitAfter(statementLog, "\n")
+
+	tests := []struct {
+		name   string
+		chunks []string
+	}{
+		{"line by line", lines},
+		{"at once", []string{statementLog}},
+		{"split lines", []string{statementLog[:50], statementLog[50:200], statementLog[200:]}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newStatementRecorder(nil)
+			done := make(chan struct{})
+			r.markers["bochka_sync_test"] = done
+
+			for _, chunk := range tt.chunks {
+				r.Accept(testcontainers.Log{LogType: testcontainers.StderrLog, Content: []byte(chunk)})
+			}
+
+			select {
+			case <-done:
+			default:
+				t.Fatal("expected the sync marker to be seen")
+			}
+
+			if len(r.statements) != 2 {
+				t.Fatalf("expected 2 statements, got %d: %+v", len(r.statements), r.statements)
+			}
+
+			first := r.statements[0]
+			if first.Query != "SELECT *\nFROM users" {
+				t.Errorf("unexpected query %q", first.Query)
+			}
+			if first.PID != 42 || first.Application != "app" {
+				t.Errorf("unexpected session %d %q", first.PID, first.Application)
+			}
+			if first.Duration != 100*time.Microsecond {
+				t.Errorf("unexpected duration %s", first.Duration)
+			}
+			if want := time.Date(2026, 10, 16, 12, 0, 0, 123e6, time.UTC); !first.Time.Equal(want) {
+				t.Errorf("unexpected time %s", first.Time)
+			}
+			if !first.UsesNode("Seq Scan") {
+				t.Errorf("expected the plan to use Seq Scan, got %s", first.Plan)
+			}
+
+			second := r.statements[1]
+			if second.Query != "SELECT name FROM users WHERE id = $1" || second.Plan != nil {
+				t.Errorf("unexpected statement %+v", second)
+			}
+
+			if got := len(r.statements.UsingNode("Seq Scan")); got != 1 {
+				t.Errorf("expected 1 statement using Seq Scan, got %d", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package bochka
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	postgresTLSUploadDir = "/tmp/bochka-tls"           // the certificates are copied here by root
+	postgresTLSDir       = "/var/lib/postgresql/certs" // and installed here, owned by postgres
+	postgresTLSValidity  = 7 * 24 * time.Hour
+)
+
+// postgresTLS holds the throwaway certificates of a TLS-enabled PostgreSQL container.
+type postgresTLS struct {
+	caPEM        []byte
+	certPEM      []byte
+	keyPEM       []byte
+	rootCertFile string // CA certificate on the host, referenced by sslrootcert in the DSN
+}
+
+// postgresTLSInstall installs the certificates with the ownership and permissions required by postgres.
+const postgresTLSInstall = `mkdir -p ` + postgresTLSDir + `
+install -o postgres -g postgres -m 0644 ` + postgresTLSUploadDir + `/server.crt ` + postgresTLSDir + `/server.crt
+install -o postgres -g postgres -m 0600 ` + postgresTLSUploadDir + `/server.key ` + postgresTLSDir + `/server.key`
+
+// postgresTLSConfig enables TLS with the installed certificates.
+var postgresTLSConfig = map[string]string{
+	"ssl":           "on",
+	"ssl_cert_file": postgresTLSDir + "/server.crt",
+	"ssl_key_file":  postgresTLSDir + "/server.key",
+}
+
+// newPostgresTLS generates a CA and a server certificate valid for the host alias, localhost
+// and the Docker daemon host, and writes the CA certificate to a temporary file.
+func newPostgresTLS(ctx context.Context, alias string) (*postgresTLS, error) {
+	hosts := []string{alias, "localhost", "127.0.0.1", "::1"}
+
+	provider, err := testcontainers.NewDockerProvider()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docker provider: %w", err)
+	}
+	defer func() { _ = provider.Close() }()
+
+	daemonHost, err := provider.DaemonHost(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get docker daemon host: %w", err)
+	}
+	hosts = append(hosts, daemonHost)
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+
+	caSerial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	caTemplate := &x509.Certificate{
+		SerialNumber:          caSerial,
+		Subject:               pkix.Name{CommonName: "bochka test CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(postgresTLSValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate server key: %w", err)
+	}
+
+	serverSerial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	serverTemplate := &x509.Certificate{
+		SerialNumber: serverSerial,
+		Subject:      pkix.Name{CommonName: alias},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(postgresTLSValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+		} else {
+			serverTemplate.DNSNames = append(serverTemplate.DNSNames, host)
+		}
+	}
+
+	serverDER, err := x509.CreateCertificate(rand.Reader, serverTemplate, caCert, &serverKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create server certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(serverKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal server key: %w", err)
+	}
+
+	certs := &postgresTLS{
+		caPEM:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverDER}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	}
+
+	rootCertFile, err := os.CreateTemp("", "bochka-ca-*.crt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate file: %w", err)
+	}
+	certs.rootCertFile = rootCertFile.Name()
+
+	if _, err = rootCertFile.Write(certs.caPEM); err != nil {
+		_ = rootCertFile.Close()
+		_ = certs.remove()
+		return nil, fmt.Errorf("failed to write CA certificate file: %w", err)
+	}
+
+	if err = rootCertFile.Close(); err != nil {
+		_ = certs.remove()
+		return nil, fmt.Errorf("failed to close CA certificate file: %w", err)
+	}
+
+	return certs, nil
+}
+
+// serialNumber returns a random 128-bit certificate serial number.
+func serialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	return serial, nil
+}
+
+// files returns the certificates to copy into the container.
+func (c *postgresTLS) files() []testcontainers.ContainerFile {
+	return []testcontainers.ContainerFile{
+		{Reader: bytes.NewReader(c.certPEM), ContainerFilePath: postgresTLSUploadDir + "/server.crt", FileMode: 0o644},
+		{Reader: bytes.NewReader(c.keyPEM), ContainerFilePath: postgresTLSUploadDir + "/server.key", FileMode: 0o600},
+	}
+}
+
+// remove deletes the CA certificate file from the host.
+func (c *postgresTLS) remove() error {
+	if c == nil || c.rootCertFile == "" {
+		return nil
+	}
+
+	if err := os.Remove(c.rootCertFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove CA certificate file: %w", err)
+	}
+
+	return nil
+}
+
+// CACertPEM returns the PEM-encoded CA certificate that signed the server certificate,
+// or nil if TLS is not enabled or the container is not started.
+func (p *PostgresService) CACertPEM() []byte {
+	if p.tls == nil {
+		return nil
+	}
+
+	return bytes.Clone(p.tls.caPEM)
+}
+
+// TLSConfig returns a client TLS configuration that trusts the generated CA and verifies the
+// server name against Host, or nil if TLS is not enabled or the container is not started.
+func (p *PostgresService) TLSConfig() *tls.Config {
+	if p.tls == nil {
+		return nil
+	}
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(p.tls.caPEM)
+
+	return &tls.Config{
+		RootCAs:    pool,
+		ServerName: p.Host(),
+		MinVersion: tls.VersionTLS12,
+	}
+}
@@ -0,0 +1,280 @@
+package bochka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+)
+
+const (
+	redisNodeAliasPrefix = "redis-node-"
+	redisClusterInterval = 200 * time.Millisecond
+	redisClusterTimeout  = time.Minute // limits the cluster setup unless WithStartupTimeout is passed
+)
+
+// RedisClusterService implements ContainerService for a Redis Cluster of masters and their replicas.
+type RedisClusterService struct {
+	nodes    []*RedisService
+	replicas int // replicas per master
+
+	mu      sync.Mutex
+	addrMap map[string]string // host-reachable addresses by the addresses the nodes announce
+}
+
+// Start starts the nodes concurrently, creates the cluster and waits until it is ready to serve
+// all slots. The cluster setup is limited by the startup timeout. Returns error on failure.
+func (c *RedisClusterService) Start(ctx context.Context) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, node := range c.nodes {
+		wg.Go(func() {
+			if err := node.Start(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	addrMap := make(map[string]string, len(c.nodes))
+	announced := make([]string, 0, len(c.nodes))
+	for _, node := range c.nodes {
+		ip, err := node.Container.ContainerIP(ctx)
+		if err != nil {
+			return &StartError{Phase: PhaseInspect, Alias: node.alias, Image: node.config.Image + ":" + node.config.Version, Err: err}
+		}
+
+		addr := net.JoinHostPort(ip, redisPort)
+		announced = append(announced, addr)
+		addrMap[addr] = node.Addr()
+	}
+
+	c.mu.Lock()
+	c.addrMap = addrMap
+	c.mu.Unlock()
+
+	first := c.nodes[0]
+	setupErr := func(err error) error {
+		return &StartError{Phase: PhaseSetup, Alias: first.alias, Image: first.config.Image + ":" + first.config.Version, Err: err}
+	}
+
+	timeout := first.config.StartupTimeout
+	if timeout <= 0 {
+		timeout = redisClusterTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	create := append([]string{"--cluster", "create"}, announced...)
+	create = append(create, "--cluster-replicas", strconv.Itoa(c.replicas), "--cluster-yes")
+	if _, err := first.cli(ctx, create...); err != nil {
+		return setupErr(fmt.Errorf("failed to create cluster: %w", err))
+	}
+
+	for _, node := range c.nodes {
+		if err := node.waitClusterOK(ctx); err != nil {
+			return setupErr(err)
+		}
+	}
+
+	return nil
+}
+
+// cli runs redis-cli inside the container as the default user and returns its combined output.
+func (r *RedisService) cli(ctx context.Context, args ...string) (string, error) {
+	var env []string
+	if r.opts.password != "" {
+		env = append(env, "REDISCLI_AUTH="+r.opts.password)
+	}
+
+	code, reader, err := r.Container.Exec(ctx, append([]string{"redis-cli"}, args...), tcexec.Multiplexed(), tcexec.WithEnv(env))
+	if err != nil {
+		return "", fmt.Errorf("failed to run redis-cli: %w", err)
+	}
+
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read redis-cli output: %w", err)
+	}
+
+	if code != 0 {
+		return string(output), fmt.Errorf("redis-cli exited with code %d: %s", code, strings.TrimSpace(string(output)))
+	}
+
+	return string(output), nil
+}
+
+// waitClusterOK waits until the node reports that the cluster serves all slots.
+func (r *RedisService) waitClusterOK(ctx context.Context) error {
+	for {
+		output, err := r.cli(ctx, "cluster", "info")
+		if err != nil {
+			return err
+		}
+
+		if strings.Contains(output, "cluster_state:ok") {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("cluster state of %s is not ok: %w", r.alias, ctx.Err())
+		case <-time.After(redisClusterInterval):
+		}
+	}
+}
+
+// Close terminates the nodes.
+func (c *RedisClusterService) Close() error {
+	var errs []error
+	for _, node := range slices.Backward(c.nodes) {
+		errs = append(errs, node.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// HostAlias returns the network alias of the first node.
+func (c *RedisClusterService) HostAlias() string {
+	return c.nodes[0].HostAlias()
+}
+
+// GetContainer returns the container of the first node.
+func (c *RedisClusterService) GetContainer() testcontainers.Container {
+	return c.nodes[0].GetContainer()
+}
+
+// containerServices returns the nodes, so the logs of all of them are printed.
+func (c *RedisClusterService) containerServices() []ContainerService {
+	services := make([]ContainerService, 0, len(c.nodes))
+	for _, node := range c.nodes {
+		services = append(services, node)
+	}
+
+	return services
+}
+
+// Nodes returns the nodes, aliased redis-node-1, redis-node-2 and so on. The cluster decides
+// which of them become masters.
+func (c *RedisClusterService) Nodes() []*RedisService {
+	return slices.Clone(c.nodes)
+}
+
+// Addrs returns the host-reachable host:port of every node, to be used as seed addresses.
+func (c *RedisClusterService) Addrs() []string {
+	addrs := make([]string, 0, len(c.nodes))
+	for _, node := range c.nodes {
+		addrs = append(addrs, node.Addr())
+	}
+
+	return addrs
+}
+
+// InternalAddrs returns alias:port of every node for connections from other containers on the same network.
+func (c *RedisClusterService) InternalAddrs() []string {
+	addrs := make([]string, 0, len(c.nodes))
+	for _, node := range c.nodes {
+		addrs = append(addrs, node.InternalAddr())
+	}
+
+	return addrs
+}
+
+// AddrMap returns the host-reachable addresses by the addresses the nodes announce in the cluster
+// topology and MOVED redirects, which are not reachable from the host.
+func (c *RedisClusterService) AddrMap() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return maps.Clone(c.addrMap)
+}
+
+// Dialer returns a dial function for cluster clients that connects to the host-reachable address
+// of the announced one, e.g. for redis.ClusterOptions.Dialer. Other addresses are dialed as is.
+func (c *RedisClusterService) Dialer() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	var dialer net.Dialer
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		c.mu.Lock()
+		if hostAddr, ok := c.addrMap[addr]; ok {
+			addr = hostAddr
+		}
+		c.mu.Unlock()
+
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
+
+// Username returns the first user defined by WithRedisUser, or an empty string for the default user.
+func (c *RedisClusterService) Username() string {
+	return c.nodes[0].Username()
+}
+
+// Password returns the password of the user reported by Username, or an empty string if the
+// default user has no password.
+func (c *RedisClusterService) Password() string {
+	return c.nodes[0].Password()
+}
+
+// NewRedisCluster creates a Redis Cluster test helper with the number of masters, at least three,
+// and the number of replicas per master. The settings apply to every node, except WithPort:
+// the host ports are chosen by Docker.
+func NewRedisCluster(t testing.TB, ctx context.Context, masters, replicas int, settings ...option) *Bochka[*RedisClusterService] {
+	t = reporter(t)
+
+	if masters < 3 {
+		t.Fatalf("redis cluster requires at least 3 masters, got %d", masters)
+	}
+
+	if replicas < 0 {
+		t.Fatalf("redis cluster replicas must not be negative, got %d", replicas)
+	}
+
+	opts := options{
+		image:   "redis",
+		version: "7-alpine",
+	}
+
+	opts.applyOptions(settings)
+	opts.port = ""
+
+	network := opts.network
+	ownsNetwork := network == nil
+	if ownsNetwork {
+		var err error
+		network, err = NewNetwork(ctx)
+		if err != nil {
+			t.Fatalf("failed to create network: %v", err)
+		}
+	}
+
+	cluster := &RedisClusterService{replicas: replicas}
+	for i := range masters * (1 + replicas) {
+		node := newRedisService(t, opts, network, redisNodeAliasPrefix+strconv.Itoa(i+1))
+		node.cluster = true
+		cluster.nodes = append(cluster.nodes, node)
+	}
+
+	return newBochka(t, ctx, opts, network, ownsNetwork, cluster)
+}
@@ -0,0 +1,71 @@
+package bochka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"testing"
+)
+
+// reporter returns t, or a no-op reporter if t is nil. It allows using the constructors outside
+// of a test, e.g. in TestMain.
+func reporter(t testing.TB) testing.TB {
+	if t == nil {
+		return &nopTB{}
+	}
+
+	return t
+}
+
+// nopTB is the reporter used when no testing.TB is passed to a constructor. Messages are written
+// to the standard logger, errors mark it as failed, fatal errors panic, and cleanups are not
+// registered, so the caller must call Close itself.
+type nopTB struct {
+	testing.TB
+	failed atomic.Bool
+}
+
+func (*nopTB) Helper() {}
+
+func (*nopTB) Name() string {
+	return "TestMain"
+}
+
+func (tb *nopTB) Failed() bool {
+	return tb.failed.Load()
+}
+
+func (*nopTB) Cleanup(func()) {}
+
+func (*nopTB) Context() context.Context {
+	return context.Background()
+}
+
+func (*nopTB) Log(args ...any) {
+	log.Print(args...)
+}
+
+func (*nopTB) Logf(format string, args ...any) {
+	log.Printf(format, args...)
+}
+
+func (tb *nopTB) Error(args ...any) {
+	tb.failed.Store(true)
+	log.Print(args...)
+}
+
+func (tb *nopTB) Errorf(format string, args ...any) {
+	tb.failed.Store(true)
+	log.Printf(format, args...)
+}
+
+func (tb *nopTB) Fatal(args ...any) {
+	tb.failed.Store(true)
+	panic(fmt.Sprint(args...))
+}
+
+func (tb *nopTB) Fatalf(format string, args ...any) {
+	tb.failed.Store(true)
+	panic(fmt.Sprintf(format, args...))
+}
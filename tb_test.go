@@ -0,0 +1,39 @@
+package bochka
+
+import "testing"
+
+func Test_nopTBFailed(t *testing.T) {
+	tb := reporter(nil)
+	if tb.Name() != "TestMain" {
+		t.Errorf("expected name TestMain, got %q", tb.Name())
+	}
+
+	tb.Log("no failure")
+	if tb.Failed() {
+		t.Fatal("expected Log not to mark the reporter as failed")
+	}
+
+	tb.Errorf("failure %d", 1)
+	if !tb.Failed() {
+		t.Error("expected Errorf to mark the reporter as failed")
+	}
+
+	if reporter(nil).Failed() {
+		t.Error("expected a new reporter not to be failed")
+	}
+}
+
+func Test_nopTBFatal(t *testing.T) {
+	tb := reporter(nil)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Fatal to panic")
+		}
+		if !tb.Failed() {
+			t.Error("expected Fatal to mark the reporter as failed")
+		}
+	}()
+
+	tb.Fatal("fatal")
+}
@@ -0,0 +1,164 @@
+package bochka_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaatinga/bochka"
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+)
+
+func Test_Environment(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 60*time.Second)
+	defer cancel()
+
+	env := bochka.NewEnvironment(t, ctx)
+	postgres := bochka.NewPostgres(t, ctx, bochka.WithEnvironment(env), bochka.WithPort("5440"))
+	dir := t.TempDir()
+	redis := bochka.NewRedis(t, ctx, bochka.WithEnvironment(env), bochka.WithPort("6393"), bochka.WithLogArtifacts(dir, false))
+	nats := bochka.NewNats(t, ctx, bochka.WithEnvironment(env), bochka.WithPort("4225"))
+
+	if got := len(env.Services()); got != 3 {
+		t.Fatalf("expected 3 services in the environment, got %d", got)
+	}
+
+	if err := env.Start(); err != nil {
+		t.Fatalf("failed to start environment: %v", err)
+	}
+
+	for _, name := range []string{postgres.NetworkName(), redis.NetworkName(), nats.NetworkName()} {
+		if name != env.NetworkName() {
+			t.Errorf("expected network %s, got %s", env.NetworkName(), name)
+		}
+	}
+
+	for _, service := range env.Services() {
+		if !service.GetContainer().IsRunning() {
+			t.Errorf("expected %s container to be running", service.HostAlias())
+		}
+	}
+
+	if err := env.Close(); err != nil {
+		t.Fatalf("failed to close environment: %v", err)
+	}
+
+	for _, service := range env.Services() {
+		if service.GetContainer().IsRunning() {
+			t.Errorf("expected %s container to be terminated", service.HostAlias())
+		}
+	}
+	// the log artifacts of the service are written by the environment
+	if _, err := os.Stat(filepath.Join(dir, t.Name(), "redis.log")); err != nil {
+		t.Errorf("expected redis log artifact: %v", err)
+	}
+}
+
+func Test_EnvironmentInternalAddresses(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 60*time.Second)
+	defer cancel()
+
+	env := bochka.NewEnvironment(t, ctx)
+	postgres := bochka.NewPostgres(t, ctx,
+		bochka.WithEnvironment(env),
+		bochka.WithPort("5442"),
+		bochka.WithDSNParams(map[string]string{"application_name": "internal", "sslmode": "disable"}),
+	)
+	redis := bochka.NewRedis(t, ctx, bochka.WithEnvironment(env), bochka.WithPort("6398"))
+	nats := bochka.NewNats(t, ctx, bochka.WithEnvironment(env), bochka.WithPort("4228"))
+
+	if err := env.Start(); err != nil {
+		t.Fatalf("failed to start environment: %v", err)
+	}
+
+	for got, want := range map[string]string{
+		postgres.Service().InternalAddr(): "postgres:5432",
+		redis.Service().InternalAddr():    "redis:6379",
+		nats.Service().InternalURL():      "nats://nats:4222",
+	} {
+		if got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	}
+
+	// the internal addresses resolve inside the network
+	output := execOutput(t, ctx, postgres.Service().GetContainer(),
+		"psql", postgres.Service().InternalDSN(), "--tuples-only", "--no-align", "--command", "SELECT current_setting('application_name')")
+	if output != "internal" {
+		t.Errorf("expected application_name internal, got %q", output)
+	}
+
+	output = execOutput(t, ctx, redis.Service().GetContainer(), "redis-cli", "-u", "redis://"+redis.Service().InternalAddr(), "ping")
+	if output != "PONG" {
+		t.Errorf("expected PONG, got %q", output)
+	}
+}
+
+// execOutput runs the command in the container and returns its trimmed output.
+func execOutput(t *testing.T, ctx context.Context, container testcontainers.Container, cmd ...string) string {
+	t.Helper()
+
+	code, reader, err := container.Exec(ctx, cmd, tcexec.Multiplexed())
+	if err != nil {
+		t.Fatalf("failed to run %s: %v", cmd[0], err)
+	}
+
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to read %s output: %v", cmd[0], err)
+	}
+
+	if code != 0 {
+		t.Fatalf("%s exited with code %d: %s", cmd[0], code, output)
+	}
+
+	return strings.TrimSpace(string(output))
+}
+
+func Test_EnvironmentDependencies(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 60*time.Second)
+	defer cancel()
+
+	env := bochka.NewEnvironment(t, ctx)
+	postgres := bochka.NewPostgres(t, ctx, bochka.WithEnvironment(env), bochka.WithPort("5441"))
+	nats := bochka.NewNats(t, ctx, bochka.WithEnvironment(env), bochka.WithPort("4226"))
+	redis := bochka.NewRedis(t, ctx,
+		bochka.WithEnvironment(env),
+		bochka.WithDependsOn(postgres.Service(), nats.Service()),
+		bochka.WithPort("6394"),
+	)
+
+	if err := env.Start(); err != nil {
+		t.Fatalf("failed to start environment: %v", err)
+	}
+
+	if !redis.Service().GetContainer().IsRunning() {
+		t.Error("expected redis container to be running")
+	}
+}
+
+func Test_EnvironmentDependencyCycle(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	env := bochka.NewEnvironment(t, ctx)
+	postgres := bochka.NewPostgres(t, ctx, bochka.WithEnvironment(env))
+	redis := bochka.NewRedis(t, ctx, bochka.WithEnvironment(env), bochka.WithDependsOn(postgres.Service()))
+	env.DependsOn(postgres.Service(), redis.Service())
+
+	err := env.Start()
+	if err == nil {
+		t.Fatal("expected a dependency cycle error")
+	}
+	if !strings.Contains(err.Error(), "dependency cycle") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if postgres.Service().GetContainer() != nil {
+		t.Error("expected no container to be started")
+	}
+}
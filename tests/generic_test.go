@@ -0,0 +1,101 @@
+package bochka_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/kaatinga/bochka"
+)
+
+func Test_GenericService(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	helper := bochka.NewGeneric(t, ctx, bochka.GenericSpec{
+		Image:     "docker.io/library/nginx",
+		Version:   "1.27-alpine",
+		HostAlias: "web",
+		Ports:     map[string]string{"http": "80/tcp"},
+	})
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start generic container: %v", err)
+	}
+
+	svc := helper.Service()
+	if svc.HostAlias() != "web" {
+		t.Errorf("expected alias 'web', got %q", svc.HostAlias())
+	}
+	if svc.Port("http") == 0 {
+		t.Error("expected non-zero http port")
+	}
+	if svc.Port("unknown") != 0 {
+		t.Error("expected zero port for an unknown name")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+svc.Addr("http"), nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to query nginx: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+func Test_StartErrorPhases(t *testing.T) {
+	t.Run("pull", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), 60*time.Second)
+		defer cancel()
+
+		helper := bochka.NewRedis(t, ctx, bochka.WithCustomImage("docker.io/library/redis", "no-such-tag-bochka"))
+		err := helper.Start()
+
+		var startErr *bochka.StartError
+		if !errors.As(err, &startErr) {
+			t.Fatalf("expected StartError, got %v", err)
+		}
+		if startErr.Phase != bochka.PhasePull {
+			t.Errorf("expected pull phase, got %s", startErr.Phase)
+		}
+		if startErr.Image != "docker.io/library/redis:no-such-tag-bochka" {
+			t.Errorf("unexpected image %q", startErr.Image)
+		}
+	})
+
+	t.Run("wait", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), 60*time.Second)
+		defer cancel()
+
+		helper := bochka.NewRedis(t, ctx,
+			bochka.WithPort("6397"),
+			bochka.WithWaitStrategy(wait.ForLog("this line is never logged")),
+			bochka.WithStartupTimeout(3*time.Second),
+		)
+		err := helper.Start()
+
+		var startErr *bochka.StartError
+		if !errors.As(err, &startErr) {
+			t.Fatalf("expected StartError, got %v", err)
+		}
+		if startErr.Phase != bochka.PhaseWait {
+			t.Errorf("expected wait phase, got %s", startErr.Phase)
+		}
+		if startErr.Alias != "redis" {
+			t.Errorf("expected alias 'redis', got %q", startErr.Alias)
+		}
+		if !strings.Contains(startErr.Logs, "Ready to accept connections") {
+			t.Errorf("expected the container logs to be attached, got %q", startErr.Logs)
+		}
+	})
+}
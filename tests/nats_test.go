@@ -2,11 +2,14 @@ package bochka_test
 
 import (
 	"context"
+	"regexp"
 	"strconv"
 	"testing"
 	"time"
 
+	"github.com/moby/moby/client"
 	"github.com/nats-io/nats.go"
+	"github.com/testcontainers/testcontainers-go"
 
 	"github.com/kaatinga/bochka"
 )
@@ -80,3 +83,39 @@ func TestNatsWithCustomEnvVars(t *testing.T) {
 	t.Logf("NATS container started with custom environment variables")
 	t.Logf("NATS connection: %s:%d", helper.Service().Host(), helper.Service().Port())
 }
+
+func TestNatsRemovesOwnNetwork(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	helper := bochka.NewNats(t, ctx, bochka.WithPort("4224"))
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start NATS container: %v", err)
+	}
+	if err := helper.Close(); err != nil {
+		t.Fatalf("failed to close helper: %v", err)
+	}
+
+	cli, err := testcontainers.NewDockerClientWithOpts(ctx)
+	if err != nil {
+		t.Fatalf("failed to create docker client: %v", err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	if _, err := cli.NetworkInspect(ctx, helper.NetworkName(), client.NetworkInspectOptions{}); err == nil {
+		t.Errorf("expected network %s to be removed", helper.NetworkName())
+	}
+}
+
+func TestNatsWithLogStream(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	helper := bochka.NewNats(t, ctx,
+		bochka.WithPort("4227"),
+		bochka.WithLogStream(bochka.LogMatching(regexp.MustCompile(`Server is ready|JetStream`))),
+	)
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start NATS container: %v", err)
+	}
+}
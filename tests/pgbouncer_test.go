@@ -0,0 +1,54 @@
+package bochka_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kaatinga/bochka"
+)
+
+func Test_PgBouncer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 60*time.Second)
+	defer cancel()
+
+	pg := bochka.NewPostgres(t, ctx, bochka.WithPort("5569"))
+	if err := pg.Start(); err != nil {
+		t.Fatalf("failed to start postgres: %v", err)
+	}
+
+	bouncer := bochka.NewPgBouncer(t, ctx, pg.Service(),
+		bochka.WithPort("6434"),
+		bochka.WithPoolMode(bochka.PoolModeTransaction),
+		bochka.WithPoolSize(2),
+	)
+	if err := bouncer.Start(); err != nil {
+		t.Fatalf("failed to start pgbouncer: %v", err)
+	}
+
+	svc := bouncer.Service()
+	if svc.PoolMode() != bochka.PoolModeTransaction {
+		t.Errorf("expected transaction pool mode, got %s", svc.PoolMode())
+	}
+	if svc.InternalAddr() != "pgbouncer:6432" {
+		t.Errorf("expected pgbouncer:6432, got %s", svc.InternalAddr())
+	}
+
+	conn, err := pgx.Connect(ctx, svc.DSN()+"?sslmode=disable")
+	if err != nil {
+		t.Fatalf("failed to connect to pgbouncer: %v", err)
+	}
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	// prepared statements across transactions are the classic transaction pooling pitfall
+	for i := range 3 {
+		var got int
+		if err := conn.QueryRow(ctx, `SELECT $1::int`, i).Scan(&got); err != nil {
+			t.Fatalf("failed to query via pgbouncer: %v", err)
+		}
+		if got != i {
+			t.Errorf("expected %d, got %d", i, got)
+		}
+	}
+}
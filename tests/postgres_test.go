@@ -2,13 +2,19 @@ package bochka_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 
 	"github.com/kaatinga/bochka"
+	"github.com/kaatinga/bochka/postgres"
 )
 
 func Test_PostgreDatabase(t *testing.T) {
@@ -144,3 +150,537 @@ func Test_PostgreDatabase(t *testing.T) {
 		t.Logf("failed to close helper: %v", closeErr)
 	}
 }
+
+func Test_PostgresLogArtifacts(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	dir := t.TempDir()
+	helper := bochka.NewPostgres(t, ctx, bochka.WithPort("5556"), bochka.WithLogArtifacts(dir, false))
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+	if err := helper.Close(); err != nil {
+		t.Fatalf("failed to close helper: %v", err)
+	}
+
+	logs, err := os.ReadFile(filepath.Join(dir, t.Name(), "postgres.log"))
+	if err != nil {
+		t.Fatalf("failed to read log artifact: %v", err)
+	}
+	if !strings.Contains(string(logs), "database system is ready to accept connections") {
+		t.Errorf("unexpected log artifact content:\n%s", logs)
+	}
+}
+
+func Test_PostgresCredentials(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	helper := bochka.NewPostgres(t, ctx,
+		bochka.WithPort("5557"),
+		bochka.WithRandomPostgresCredentials(),
+		bochka.WithPostgresUser("app"),
+		bochka.WithPostgresDB("orders"),
+	)
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+
+	svc := helper.Service()
+	if svc.User() != "app" {
+		t.Errorf("expected user 'app', got %q", svc.User())
+	}
+	if svc.DBName() != "orders" {
+		t.Errorf("expected database 'orders', got %q", svc.DBName())
+	}
+	if svc.Password() == "" || svc.Password() == "12345" {
+		t.Errorf("expected a random password, got %q", svc.Password())
+	}
+
+	conn, err := pgx.Connect(ctx, svc.DSN()+"?sslmode=disable")
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	var user, dbName string
+	if err := conn.QueryRow(ctx, `SELECT current_user, current_database()`).Scan(&user, &dbName); err != nil {
+		t.Fatalf("failed to query credentials: %v", err)
+	}
+	if user != "app" || dbName != "orders" {
+		t.Errorf("expected app@orders, got %s@%s", user, dbName)
+	}
+}
+
+func Test_PostgresMigrations(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	helper := bochka.NewPostgres(t, ctx, bochka.WithPort("5558"), bochka.WithMigrationsDir("testdata/migrations"))
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+
+	// applying the same migrations again is a no-op
+	if err := helper.Service().Migrate(ctx, os.DirFS("testdata/migrations")); err != nil {
+		t.Fatalf("failed to migrate again: %v", err)
+	}
+
+	conn, err := pgx.Connect(ctx, helper.Service().DSN()+"?sslmode=disable")
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	if _, err := conn.Exec(ctx, `INSERT INTO users (name, email) VALUES ('bochka', 'bochka@example.com')`); err != nil {
+		t.Fatalf("failed to insert into migrated table: %v", err)
+	}
+
+	var applied int
+	if err := conn.QueryRow(ctx, `SELECT count(*) FROM `+bochka.MigrationsTable).Scan(&applied); err != nil {
+		t.Fatalf("failed to count applied migrations: %v", err)
+	}
+	if applied != 2 {
+		t.Errorf("expected 2 applied migrations, got %d", applied)
+	}
+}
+
+func Test_PostgresFailedMigration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	migrations := fstest.MapFS{
+		"1_ok.up.sql":     {Data: []byte(`CREATE TABLE ok (id int);`)},
+		"2_broken.up.sql": {Data: []byte(`CREATE TABLE broken (id nosuchtype);`)},
+	}
+
+	helper := bochka.NewPostgres(t, ctx, bochka.WithPort("5559"), bochka.WithMigrations(migrations))
+	err := helper.Start()
+
+	var startErr *bochka.StartError
+	if !errors.As(err, &startErr) || startErr.Phase != bochka.PhaseSetup {
+		t.Fatalf("expected setup phase StartError, got %v", err)
+	}
+	for _, want := range []string{"2_broken.up.sql", "nosuchtype"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected the error to mention %q, got %v", want, err)
+		}
+	}
+}
+
+func Test_PostgresInitScriptsAndFixtures(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	helper := bochka.NewPostgres(t, ctx, bochka.WithPort("5560"), bochka.WithInitScripts("testdata/initdb"))
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+
+	// authors must be loaded first because of the foreign key
+	err := helper.Service().LoadFixtures(ctx, os.DirFS("testdata/fixtures"), "authors.csv", "books.json")
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+
+	conn, err := pgx.Connect(ctx, helper.Service().DSN()+"?sslmode=disable")
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	var books int
+	var comment string
+	err = conn.QueryRow(ctx, `SELECT count(*), obj_description('books'::regclass) FROM books JOIN authors ON authors.id = books.author_id`).Scan(&books, &comment)
+	if err != nil {
+		t.Fatalf("failed to query fixtures: %v", err)
+	}
+	if books != 2 {
+		t.Errorf("expected 2 books, got %d", books)
+	}
+	if comment != "created by bochka init scripts" {
+		t.Errorf("expected the shell init script to run, got comment %q", comment)
+	}
+
+	// the key missing from the JSON object falls back to the column default
+	var edition int
+	err = conn.QueryRow(ctx, `SELECT edition FROM books WHERE title = 'The Cherry Orchard'`).Scan(&edition)
+	if err != nil {
+		t.Fatalf("failed to query edition: %v", err)
+	}
+	if edition != 1 {
+		t.Errorf("expected the default edition 1, got %d", edition)
+	}
+}
+
+func Test_PostgresTestDatabases(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 60*time.Second)
+	defer cancel()
+
+	helper := bochka.NewPostgres(t, ctx, bochka.WithPort("5561"), bochka.WithMigrationsDir("testdata/migrations"))
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+
+	// an open connection to the service database does not prevent taking the template
+	open, err := pgx.Connect(ctx, helper.Service().DSN()+"?sslmode=disable")
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+	defer func() { _ = open.Close(context.Background()) }()
+
+	// the group returns only after the parallel subtests finish, before ctx is canceled
+	t.Run("group", func(t *testing.T) {
+		for i := range 4 {
+			t.Run(fmt.Sprintf("isolated_%d", i), func(t *testing.T) {
+				t.Parallel()
+
+				conn, err := pgx.Connect(ctx, helper.Service().NewTestDatabase(t)+"?sslmode=disable")
+				if err != nil {
+					t.Fatalf("failed to connect to test database: %v", err)
+				}
+				defer func() { _ = conn.Close(context.Background()) }()
+
+				// every database starts from the migrated template
+				var users int
+				if err := conn.QueryRow(ctx, `SELECT count(*) FROM users`).Scan(&users); err != nil {
+					t.Fatalf("failed to query users: %v", err)
+				}
+				if users != 0 {
+					t.Errorf("expected an empty users table, got %d rows", users)
+				}
+
+				if _, err := conn.Exec(ctx, `INSERT INTO users (name) VALUES ($1)`, t.Name()); err != nil {
+					t.Fatalf("failed to insert user: %v", err)
+				}
+			})
+		}
+	})
+}
+
+func Test_PostgresSnapshotRestore(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 60*time.Second)
+	defer cancel()
+
+	helper := bochka.NewPostgres(t, ctx, bochka.WithPort("5562"), bochka.WithMigrationsDir("testdata/migrations"))
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+	svc := helper.Service()
+
+	exec := func(query string) {
+		t.Helper()
+
+		conn, err := pgx.Connect(ctx, svc.DSN()+"?sslmode=disable")
+		if err != nil {
+			t.Fatalf("failed to connect to postgres: %v", err)
+		}
+		defer func() { _ = conn.Close(context.Background()) }()
+
+		if _, err := conn.Exec(ctx, query); err != nil {
+			t.Fatalf("failed to execute %q: %v", query, err)
+		}
+	}
+	countUsers := func() (users int) {
+		t.Helper()
+
+		conn, err := pgx.Connect(ctx, svc.DSN()+"?sslmode=disable")
+		if err != nil {
+			t.Fatalf("failed to connect to postgres: %v", err)
+		}
+		defer func() { _ = conn.Close(context.Background()) }()
+
+		if err := conn.QueryRow(ctx, `SELECT count(*) FROM users`).Scan(&users); err != nil {
+			t.Fatalf("failed to count users: %v", err)
+		}
+		return users
+	}
+
+	exec(`INSERT INTO users (name) VALUES ('first')`)
+	if err := svc.Snapshot(ctx, "one_user"); err != nil {
+		t.Fatalf("failed to take snapshot: %v", err)
+	}
+
+	for range 2 {
+		exec(`INSERT INTO users (name) VALUES ('second'), ('third')`)
+		if got := countUsers(); got != 3 {
+			t.Fatalf("expected 3 users before restore, got %d", got)
+		}
+
+		if err := svc.Restore(ctx, "one_user"); err != nil {
+			t.Fatalf("failed to restore snapshot: %v", err)
+		}
+		if got := countUsers(); got != 1 {
+			t.Errorf("expected 1 user after restore, got %d", got)
+		}
+	}
+
+	if err := svc.Restore(ctx, "unknown"); err == nil {
+		t.Error("expected an error for an unknown snapshot")
+	}
+}
+
+func Test_PostgresFastMode(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	helper := bochka.NewPostgres(t, ctx,
+		bochka.WithPort("5563"),
+		bochka.WithFastMode(),
+		bochka.WithPostgresConfig(map[string]string{"max_connections": "42"}),
+	)
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+
+	conn := postgres.Pool(t, helper.Service())
+
+	for setting, want := range map[string]string{
+		"fsync":              "off",
+		"synchronous_commit": "off",
+		"full_page_writes":   "off",
+		"max_connections":    "42",
+	} {
+		var got string
+		if err := conn.QueryRow(ctx, `SELECT current_setting($1)`, setting).Scan(&got); err != nil {
+			t.Fatalf("failed to read %s: %v", setting, err)
+		}
+		if got != want {
+			t.Errorf("expected %s=%s, got %s", setting, want, got)
+		}
+	}
+}
+
+func Test_PostgresExtensions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 60*time.Second)
+	defer cancel()
+
+	helper := bochka.NewPostgres(t, ctx,
+		bochka.WithPort("5564"),
+		bochka.WithExtensions("pgcrypto", "uuid-ossp", "pg_trgm"),
+	)
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+
+	conn := postgres.Pool(t, helper.Service())
+
+	var count int
+	if err := conn.QueryRow(ctx, `SELECT count(*) FROM pg_extension WHERE extname IN ('pgcrypto', 'uuid-ossp', 'pg_trgm')`).Scan(&count); err != nil {
+		t.Fatalf("failed to query extensions: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 extensions, got %d", count)
+	}
+}
+
+func Test_PostgresMissingExtension(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 60*time.Second)
+	defer cancel()
+
+	helper := bochka.NewPostgres(t, ctx,
+		bochka.WithPort("5565"),
+		bochka.WithExtensions("no_such_extension"),
+	)
+
+	err := helper.Start()
+
+	var startErr *bochka.StartError
+	if !errors.As(err, &startErr) {
+		t.Fatalf("expected StartError, got %v", err)
+	}
+	if startErr.Phase != bochka.PhaseSetup {
+		t.Errorf("expected setup phase, got %s", startErr.Phase)
+	}
+	if !strings.Contains(err.Error(), "no_such_extension") {
+		t.Errorf("expected the missing extension in the error, got %v", err)
+	}
+}
+
+func Test_PostgresTLS(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 60*time.Second)
+	defer cancel()
+
+	helper := bochka.NewPostgres(t, ctx,
+		bochka.WithPort("5566"),
+		bochka.WithTLS(),
+	)
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+
+	if len(helper.Service().CACertPEM()) == 0 {
+		t.Fatal("expected CA certificate")
+	}
+
+	dsn := helper.Service().DSN()
+	if !strings.Contains(dsn, "sslmode=verify-full") {
+		t.Errorf("expected verify-full in DSN, got %s", dsn)
+	}
+
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	var ssl bool
+	if err := conn.QueryRow(ctx, `SELECT ssl FROM pg_stat_ssl WHERE pid = pg_backend_pid()`).Scan(&ssl); err != nil {
+		t.Fatalf("failed to query ssl status: %v", err)
+	}
+	if !ssl {
+		t.Error("expected TLS connection")
+	}
+
+	config, err := pgx.ParseConfig(helper.Service().DSN())
+	if err != nil {
+		t.Fatalf("failed to parse DSN: %v", err)
+	}
+	config.TLSConfig = helper.Service().TLSConfig()
+
+	tlsConn, err := pgx.ConnectConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("failed to connect with TLSConfig: %v", err)
+	}
+	_ = tlsConn.Close(context.Background())
+}
+
+func Test_PostgresConnectionHelpers(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	helper := bochka.NewPostgres(t, ctx, bochka.WithPort("5567"))
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+
+	db := postgres.OpenDB(t, helper.Service())
+	if _, err := db.ExecContext(ctx, `CREATE TABLE items (name TEXT)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	pool := postgres.Pool(t, helper.Service())
+	if _, err := pool.Exec(ctx, `INSERT INTO items (name) VALUES ($1)`, "bochka"); err != nil {
+		t.Fatalf("failed to insert row: %v", err)
+	}
+
+	var name string
+	if err := db.QueryRowContext(ctx, `SELECT name FROM items`).Scan(&name); err != nil {
+		t.Fatalf("failed to query row: %v", err)
+	}
+	if name != "bochka" {
+		t.Errorf("expected bochka, got %s", name)
+	}
+}
+
+func Test_PostgresReplicaSet(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 90*time.Second)
+	defer cancel()
+
+	dir := t.TempDir()
+	helper := bochka.NewPostgresReplicaSet(t, ctx, 2, bochka.WithPort("5568"), bochka.WithFastMode(), bochka.WithLogArtifacts(dir, false))
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start replica set: %v", err)
+	}
+
+	set := helper.Service()
+	if got := len(set.Replicas()); got != 2 {
+		t.Fatalf("expected 2 replicas, got %d", got)
+	}
+
+	primary := postgres.Pool(t, set.Primary())
+	if _, err := primary.Exec(ctx, `CREATE TABLE events (name TEXT); INSERT INTO events VALUES ('replicated')`); err != nil {
+		t.Fatalf("failed to write to primary: %v", err)
+	}
+
+	if err := set.WaitForReplicaCatchUp(ctx); err != nil {
+		t.Fatalf("failed to wait for replicas: %v", err)
+	}
+
+	for _, replica := range set.Replicas() {
+		var name string
+		if err := postgres.Pool(t, replica).QueryRow(ctx, `SELECT name FROM events`).Scan(&name); err != nil {
+			t.Fatalf("failed to read from %s: %v", replica.HostAlias(), err)
+		}
+		if name != "replicated" {
+			t.Errorf("expected replicated row on %s, got %q", replica.HostAlias(), name)
+		}
+	}
+
+	promoted := set.Replicas()[0]
+	conn := postgres.Pool(t, promoted)
+	if _, err := conn.Exec(ctx, `INSERT INTO events VALUES ('standby')`); err == nil {
+		t.Fatal("expected write to a standby to fail")
+	}
+
+	if err := set.Promote(ctx, promoted); err != nil {
+		t.Fatalf("failed to promote replica: %v", err)
+	}
+
+	if _, err := conn.Exec(ctx, `INSERT INTO events VALUES ('promoted')`); err != nil {
+		t.Fatalf("failed to write to promoted replica: %v", err)
+	}
+
+	if err := helper.Close(); err != nil {
+		t.Fatalf("failed to close helper: %v", err)
+	}
+
+	for _, alias := range []string{"postgres", "postgres-replica-1", "postgres-replica-2"} {
+		if _, err := os.Stat(filepath.Join(dir, t.Name(), alias+".log")); err != nil {
+			t.Errorf("expected log artifact of %s: %v", alias, err)
+		}
+	}
+}
+
+func Test_PostgresStatementLog(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 60*time.Second)
+	defer cancel()
+
+	helper := bochka.NewPostgres(t, ctx,
+		bochka.WithPort("5570"),
+		bochka.WithStatementLog(),
+		bochka.WithMigrationsDir("testdata/migrations"),
+	)
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+
+	recorder := helper.Service().Statements()
+
+	statements, err := recorder.Statements(ctx)
+	if err != nil {
+		t.Fatalf("failed to get statements: %v", err)
+	}
+	if got := statements.Containing(bochka.MigrationsTable); len(got) != 0 {
+		t.Errorf("expected bochka statements to be skipped, got %v", got)
+	}
+
+	pool := postgres.Pool(t, helper.Service())
+	if err := recorder.Reset(ctx); err != nil {
+		t.Fatalf("failed to reset statements: %v", err)
+	}
+
+	for _, name := range []string{"first", "second"} {
+		var count int
+		if err := pool.QueryRow(ctx, `SELECT count(*) FROM users WHERE name = $1`, name).Scan(&count); err != nil {
+			t.Fatalf("failed to query users: %v", err)
+		}
+	}
+
+	statements, err = recorder.Statements(ctx)
+	if err != nil {
+		t.Fatalf("failed to get statements: %v", err)
+	}
+
+	users := statements.Containing("FROM users")
+	if len(users) != 2 {
+		t.Fatalf("expected 2 statements, got %d: %v", len(users), statements)
+	}
+	if got := users.UsingNode("Seq Scan"); len(got) != 2 {
+		t.Errorf("expected 2 sequential scans, got %d", len(got))
+	}
+	if users[0].Time.IsZero() || users[0].PID == 0 {
+		t.Errorf("expected time and PID, got %+v", users[0])
+	}
+}
@@ -3,10 +3,13 @@ package bochka_test
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/testcontainers/testcontainers-go/wait"
 
 	"github.com/kaatinga/bochka"
 )
@@ -76,3 +79,193 @@ func Test_RedisWithCustomPort(t *testing.T) {
 		t.Errorf("expected value 'ok', got %q", val)
 	}
 }
+
+func Test_RedisCleanup(t *testing.T) {
+	var helper *bochka.Bochka[*bochka.RedisService]
+	t.Run("start", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+		defer cancel()
+
+		helper = bochka.NewRedis(t, ctx, bochka.WithPort("6391"))
+		if err := helper.Start(); err != nil {
+			t.Fatalf("failed to start Redis container: %v", err)
+		}
+	})
+
+	if helper.Service().GetContainer().IsRunning() {
+		t.Error("expected the container to be terminated by the subtest cleanup")
+	}
+}
+
+func Test_RedisWithoutTB(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	dir := t.TempDir()
+	helper := bochka.NewRedis(nil, ctx, bochka.WithPort("6400"), bochka.WithLogArtifacts(dir, false))
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start Redis container: %v", err)
+	}
+
+	rdb := redis.NewClient(&redis.Options{Addr: helper.Service().Addr()})
+	defer func() { _ = rdb.Close() }()
+
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		t.Errorf("redis ping: %v", err)
+	}
+
+	// no cleanup is registered without a TB, so the helper is closed explicitly
+	if err := helper.Close(); err != nil {
+		t.Fatalf("failed to close helper: %v", err)
+	}
+
+	if helper.Service().GetContainer().IsRunning() {
+		t.Error("expected the container to be terminated by Close")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "TestMain", "redis.log")); err != nil {
+		t.Errorf("expected log artifact named after TestMain: %v", err)
+	}
+}
+
+func Benchmark_RedisSet(b *testing.B) {
+	ctx, cancel := context.WithTimeout(b.Context(), 30*time.Second)
+	defer cancel()
+
+	helper := bochka.NewRedis(b, ctx, bochka.WithPort("6392"))
+	if err := helper.Start(); err != nil {
+		b.Fatalf("failed to start Redis container: %v", err)
+	}
+
+	rdb := redis.NewClient(&redis.Options{Addr: helper.Service().Addr()})
+	defer func() { _ = rdb.Close() }()
+
+	for b.Loop() {
+		if err := rdb.Set(ctx, "bochka-bench", "ok", 0).Err(); err != nil {
+			b.Fatalf("redis set: %v", err)
+		}
+	}
+}
+
+func Test_RedisWaitStrategy(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	helper := bochka.NewRedis(t, ctx,
+		bochka.WithPort("6395"),
+		bochka.WithStartupTimeout(20*time.Second),
+		bochka.WithAdditionalWait(wait.ForExec([]string{"redis-cli", "ping"})),
+	)
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start Redis container: %v", err)
+	}
+}
+
+func Test_RedisStartupTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	helper := bochka.NewRedis(t, ctx,
+		bochka.WithPort("6396"),
+		bochka.WithWaitStrategy(wait.ForLog("this line is never logged")),
+		bochka.WithStartupTimeout(3*time.Second),
+	)
+
+	started := time.Now()
+	if err := helper.Start(); err == nil {
+		t.Fatal("expected the readiness check to time out")
+	}
+	if elapsed := time.Since(started); elapsed > 20*time.Second {
+		t.Errorf("expected the startup timeout to be applied, startup took %s", elapsed)
+	}
+}
+
+func Test_RedisAuth(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	helper := bochka.NewRedis(t, ctx,
+		bochka.WithPort("6399"),
+		bochka.WithRedisPassword("secret"),
+		bochka.WithRedisUser("reader", "readpass", "~cache:*", "+get", "+@connection"),
+	)
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+
+	svc := helper.Service()
+	if svc.Username() != "reader" || svc.Password() != "readpass" {
+		t.Errorf("expected reader credentials, got %q:%q", svc.Username(), svc.Password())
+	}
+
+	anonymous := redis.NewClient(&redis.Options{Addr: svc.Addr()})
+	defer func() { _ = anonymous.Close() }()
+	if err := anonymous.Ping(ctx).Err(); err == nil {
+		t.Error("expected ping without password to fail")
+	}
+
+	admin := redis.NewClient(&redis.Options{Addr: svc.Addr(), Password: "secret"})
+	defer func() { _ = admin.Close() }()
+	if err := admin.Set(ctx, "cache:key", "value", 0).Err(); err != nil {
+		t.Fatalf("failed to set key as default user: %v", err)
+	}
+
+	options, err := redis.ParseURL(svc.URL())
+	if err != nil {
+		t.Fatalf("failed to parse URL %s: %v", svc.URL(), err)
+	}
+
+	reader := redis.NewClient(options)
+	defer func() { _ = reader.Close() }()
+
+	if got, err := reader.Get(ctx, "cache:key").Result(); err != nil || got != "value" {
+		t.Errorf("expected value, got %q, %v", got, err)
+	}
+	if err := reader.Set(ctx, "cache:key", "other", 0).Err(); err == nil {
+		t.Error("expected set to be denied for the reader")
+	}
+}
+
+func Test_RedisCluster(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 90*time.Second)
+	defer cancel()
+
+	helper := bochka.NewRedisCluster(t, ctx, 3, 1, bochka.WithRedisPassword("secret"))
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start cluster: %v", err)
+	}
+
+	cluster := helper.Service()
+	if got := len(cluster.Nodes()); got != 6 {
+		t.Fatalf("expected 6 nodes, got %d", got)
+	}
+	if got := len(cluster.AddrMap()); got != 6 {
+		t.Errorf("expected 6 mapped addresses, got %d", got)
+	}
+
+	client := redis.NewClusterClient(&redis.ClusterOptions{
+		Addrs:    cluster.Addrs(),
+		Password: cluster.Password(),
+		Dialer:   cluster.Dialer(),
+	})
+	defer func() { _ = client.Close() }()
+
+	// the keys spread over the slots of all masters, so the client follows MOVED redirects
+	for i := range 20 {
+		key := fmt.Sprintf("key:%d", i)
+		if err := client.Set(ctx, key, i, 0).Err(); err != nil {
+			t.Fatalf("failed to set %s: %v", key, err)
+		}
+	}
+
+	for i := range 20 {
+		key := fmt.Sprintf("key:%d", i)
+		got, err := client.Get(ctx, key).Int()
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", key, err)
+		}
+		if got != i {
+			t.Errorf("expected %d for %s, got %d", i, key, got)
+		}
+	}
+}
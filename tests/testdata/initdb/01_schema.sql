@@ -0,0 +1,12 @@
+CREATE TABLE authors (
+    id   INT PRIMARY KEY,
+    name TEXT NOT NULL
+);
+
+CREATE TABLE books (
+    id        SERIAL PRIMARY KEY,
+    author_id INT NOT NULL REFERENCES authors (id),
+    title     TEXT NOT NULL,
+    year      INT,
+    edition   INT NOT NULL DEFAULT 1
+);
@@ -0,0 +1,3 @@
+#!/bin/sh
+set -e
+psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" -c "COMMENT ON TABLE books IS 'created by bochka init scripts'"